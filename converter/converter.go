@@ -1,13 +1,108 @@
 package converter
 
-import "strings"
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// KanaConverterStage is a synchronous conversion stage. Push is called for
+// every rune in order and Flush once at the end of the input; both append
+// their output to dst and return the extended slice. Flush must reset all the
+// state of the stage, as a KanaConverter reuses its stages for the following
+// conversions.
+type KanaConverterStage interface {
+	Push(dst []KanaConverterRune, r KanaConverterRune) []KanaConverterRune
+	Flush(dst []KanaConverterRune) []KanaConverterRune
+}
+
+// KanaConverterFunc is a KanaConverterStage that keeps no state between runes.
+type KanaConverterFunc func(dst []KanaConverterRune, r KanaConverterRune) []KanaConverterRune
+
+func (f KanaConverterFunc) Push(dst []KanaConverterRune, r KanaConverterRune) []KanaConverterRune {
+	return f(dst, r)
+}
+
+func (f KanaConverterFunc) Flush(dst []KanaConverterRune) []KanaConverterRune {
+	return dst
+}
+
+// ConvertForKanaConverter runs in through stages without any goroutines.
+func ConvertForKanaConverter(in string, stages []KanaConverterStage) string {
+	src := appendKanaConverterRunes(make([]KanaConverterRune, 0, len(in)), in)
+	src, _ = runKanaConverterStages(stages, src, make([]KanaConverterRune, 0, len(src)), true)
+
+	var b strings.Builder
+	b.Grow(len(in))
+	for _, r := range src {
+		b.WriteRune(r.Rune)
+	}
+	return b.String()
+}
+
+// appendKanaConverterRunes appends the runes of in to dst with their byte
+// offsets. Invalid UTF-8 is decoded to U+FFFD byte by byte.
+func appendKanaConverterRunes(dst []KanaConverterRune, in string) []KanaConverterRune {
+	for i := 0; i < len(in); {
+		r, size := utf8.DecodeRuneInString(in[i:])
+		dst = append(dst, KanaConverterRune{Rune: r, Start: i, End: i + size})
+		i += size
+	}
+	return dst
+}
+
+// appendKanaConverterBytes is appendKanaConverterRunes for a byte slice,
+// starting the offsets at offset.
+func appendKanaConverterBytes(dst []KanaConverterRune, in []byte, offset int) []KanaConverterRune {
+	for i := 0; i < len(in); {
+		r, size := utf8.DecodeRune(in[i:])
+		dst = append(dst, KanaConverterRune{Rune: r, Start: offset + i, End: offset + i + size})
+		i += size
+	}
+	return dst
+}
+
+// runKanaConverterStages passes src through every stage in turn, using tmp as
+// the second buffer, and returns the output with the buffer left spare.
+// Flush is only called when final is true, so stage state carries over to the
+// next call otherwise.
+func runKanaConverterStages(stages []KanaConverterStage, src, tmp []KanaConverterRune, final bool) (out, spare []KanaConverterRune) {
+	for _, s := range stages {
+		dst := tmp[:0]
+		for _, r := range src {
+			dst = s.Push(dst, r)
+		}
+		if final {
+			dst = s.Flush(dst)
+		}
+		src, tmp = dst, src
+	}
+	return src, tmp
+}
+
+func convertForKanaConverter(s KanaConverterStage, in <-chan KanaConverterRune) <-chan KanaConverterRune {
+	out := make(chan KanaConverterRune)
+	go func() {
+		defer close(out)
+		var buf []KanaConverterRune
+		for r := range in {
+			buf = s.Push(buf[:0], r)
+			for _, r := range buf {
+				out <- r
+			}
+		}
+		for _, r := range s.Flush(buf[:0]) {
+			out <- r
+		}
+	}()
+	return out
+}
 
 func GenerateForKanaConverter(in string) <-chan KanaConverterRune {
 	out := make(chan KanaConverterRune)
 	go func() {
 		defer close(out)
-		for _, r := range in {
-			out <- KanaConverterRune{Rune: r}
+		for _, r := range appendKanaConverterRunes(nil, in) {
+			out <- r
 		}
 	}()
 	return out
@@ -0,0 +1,84 @@
+package converter_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ArmadaSuit/udf-go/converter"
+)
+
+func convertByChannel(in string, mode string) (string, error) {
+	converters, err := converter.NewKanaConverters(mode)
+	if err != nil {
+		return "", err
+	}
+	c := converter.GenerateForKanaConverter(in)
+	for _, f := range converters {
+		c = f(c)
+	}
+	return converter.StringForKanaConverter(c), nil
+}
+
+var testModes = []string{
+	"", "r", "R", "n", "N", "a", "A", "s", "S", "k", "K", "h", "H", "c", "C", "V",
+	"KV", "HV", "kh", "kH", "kHV", "Kc", "KcV", "KC", "KCV", "Kh", "KhV", "HC", "HCV", "Hc", "HcV",
+	"rns", "RNS", "as", "AS", "KVas", "KVRNS", "nHV", "hH",
+	"M", "m", "KVM", "KVm", "HM", "kM", "km", "cM", "Mm",
+	"j", "J", "D", "nj", "NJ", "KVj", "jJ",
+}
+
+func TestConvertForKanaConverter(t *testing.T) {
+	inputs := []string{
+		"",
+		"The quick brown fox jumps over the lazy dog. 0123456789",
+		" !\"#$%&'()*+,-./0123456789:;<=>?@ABCDEFGHIJKLMNOPQRSTUVWXYZ[\\]^_`abcdefghijklmnopqrstuvwxyz{|}~",
+		" ！”＃＄％＆＇（）＊＋，－．／０１２３４５６７８９：；＜＝＞？＠ＡＢＣＤＥＦＧＨＩＪＫＬＭＮＯＰＱＲＳＴＵＶＷＸＹＺ［＼］＾＿｀ａｂｃｄｅｆｇｈｉｊｋｌｍｎｏｐｑｒｓｔｕｖｗｘｙｚ｛｜｝～",
+		"「ﾎﾞｰﾙﾍﾟﾝノ芯ノ太サハ、0.7mmデス。」",
+		"｢ぼーるぺんﾉ芯ﾉ太ｻﾊ､0.7mmﾃﾞｽ｡｣",
+		"ｶﾞｷﾞｸﾞｹﾞｺﾞﾊﾟﾋﾟﾌﾟﾍﾟﾎﾟｳﾞﾞﾟｱﾞﾝﾟｶ",
+		"ｶ１ﾞﾊ　ﾟ",
+		"アイウエオガギグゲゴパピプペポヴヰヱヽヾ、。「」・ー゛゜",
+		"あいうえおがぎぐげごぱぴぷぺぽゐゑゝゞ、。「」・ー゛゜",
+	}
+	for _, mode := range testModes {
+		mode := mode
+		t.Run(mode, func(t *testing.T) {
+
+			t.Parallel()
+
+			for _, in := range inputs {
+				want, wantErr := convertByChannel(in, mode)
+				stages, err := converter.NewKanaConverterStages(mode)
+				if (err != nil) != (wantErr != nil) {
+					t.Fatalf("NewKanaConverterStages() error = %v, want %v", err, wantErr)
+				}
+				if err != nil {
+					return
+				}
+				if got := converter.ConvertForKanaConverter(in, stages); got != want {
+					t.Errorf("%v is converted %v, want %v", in, got, want)
+				}
+			}
+		})
+	}
+}
+
+var benchmarkInput = strings.Repeat("「ﾎﾞｰﾙﾍﾟﾝノ芯ノ太サハ、0.7mmデス。」The quick brown fox　ｶﾞｷﾞｸﾞ", 4)
+
+func BenchmarkNewKanaConverters(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		if _, err := convertByChannel(benchmarkInput, "KVas"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkConvertForKanaConverter(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		stages, err := converter.NewKanaConverterStages("KVas")
+		if err != nil {
+			b.Fatal(err)
+		}
+		converter.ConvertForKanaConverter(benchmarkInput, stages)
+	}
+}
@@ -3,1159 +3,1200 @@ package converter
 type KanaConverterRune struct {
 	Rune        rune
 	IsConverted bool
+	// Start and End are the byte offsets of the input the rune is converted from.
+	Start int
+	End   int
+	// Stage is the name of the stage which converted the rune. It is only set
+	// by KanaConverter.Explain.
+	Stage string
+}
+
+// convert returns the rune converted to c, keeping its position in the input.
+func (r KanaConverterRune) convert(c rune) KanaConverterRune {
+	return KanaConverterRune{Rune: c, IsConverted: true, Start: r.Start, End: r.End}
+}
+
+// compose returns c converted from r followed by mark, covering both of them
+// in the input.
+func (r KanaConverterRune) compose(mark KanaConverterRune, c rune) KanaConverterRune {
+	return KanaConverterRune{Rune: c, IsConverted: true, Start: r.Start, End: mark.End}
 }
 
 func HankakuEnglishToZenkakuEnglish(in <-chan KanaConverterRune) <-chan KanaConverterRune {
-	out := make(chan KanaConverterRune)
-	go func() {
-		defer close(out)
-		for r := range in {
-			if r.IsConverted {
-				out <- r
-				continue
-			}
-			switch {
-			case r.Rune >= 'a' && r.Rune <= 'z':
-				out <- KanaConverterRune{Rune: 'ａ' + r.Rune - 'a', IsConverted: true}
-			case r.Rune >= 'A' && r.Rune <= 'Z':
-				out <- KanaConverterRune{Rune: 'Ａ' + r.Rune - 'A', IsConverted: true}
-			default:
-				out <- r
-			}
-		}
-	}()
-	return out
+	return convertForKanaConverter(KanaConverterFunc(hankakuEnglishToZenkakuEnglish), in)
+}
+
+func hankakuEnglishToZenkakuEnglish(dst []KanaConverterRune, r KanaConverterRune) []KanaConverterRune {
+	if r.IsConverted {
+		return append(dst, r)
+	}
+	switch {
+	case r.Rune >= 'a' && r.Rune <= 'z':
+		dst = append(dst, r.convert('ａ'+r.Rune-'a'))
+	case r.Rune >= 'A' && r.Rune <= 'Z':
+		dst = append(dst, r.convert('Ａ'+r.Rune-'A'))
+	default:
+		dst = append(dst, r)
+	}
+	return dst
 }
 
 func ZenkakuEnglishToHankakuEnglish(in <-chan KanaConverterRune) <-chan KanaConverterRune {
-	out := make(chan KanaConverterRune)
-	go func() {
-		defer close(out)
-		for r := range in {
-			if r.IsConverted {
-				out <- r
-				continue
-			}
-			switch {
-			case r.Rune >= 'ａ' && r.Rune <= 'ｚ':
-				out <- KanaConverterRune{Rune: 'a' + r.Rune - 'ａ', IsConverted: true}
-			case r.Rune >= 'Ａ' && r.Rune <= 'Ｚ':
-				out <- KanaConverterRune{Rune: 'A' + r.Rune - 'Ａ', IsConverted: true}
-			default:
-				out <- r
-			}
-		}
-	}()
-	return out
+	return convertForKanaConverter(KanaConverterFunc(zenkakuEnglishToHankakuEnglish), in)
+}
+
+func zenkakuEnglishToHankakuEnglish(dst []KanaConverterRune, r KanaConverterRune) []KanaConverterRune {
+	if r.IsConverted {
+		return append(dst, r)
+	}
+	switch {
+	case r.Rune >= 'ａ' && r.Rune <= 'ｚ':
+		dst = append(dst, r.convert('a'+r.Rune-'ａ'))
+	case r.Rune >= 'Ａ' && r.Rune <= 'Ｚ':
+		dst = append(dst, r.convert('A'+r.Rune-'Ａ'))
+	default:
+		dst = append(dst, r)
+	}
+	return dst
 }
 
 func HankakuNumberToZenkakuNumber(in <-chan KanaConverterRune) <-chan KanaConverterRune {
-	out := make(chan KanaConverterRune)
-	go func() {
-		defer close(out)
-		for r := range in {
-			if r.IsConverted {
-				out <- r
-				continue
-			}
-			if r.Rune >= '0' && r.Rune <= '9' {
-				out <- KanaConverterRune{Rune: r.Rune + 0xFEE0, IsConverted: true}
-			} else {
-				out <- r
-			}
-		}
-	}()
-	return out
+	return convertForKanaConverter(KanaConverterFunc(hankakuNumberToZenkakuNumber), in)
+}
+
+func hankakuNumberToZenkakuNumber(dst []KanaConverterRune, r KanaConverterRune) []KanaConverterRune {
+	if r.IsConverted {
+		return append(dst, r)
+	}
+	if r.Rune >= '0' && r.Rune <= '9' {
+		dst = append(dst, r.convert(r.Rune+0xFEE0))
+	} else {
+		dst = append(dst, r)
+	}
+	return dst
 }
 
 func ZenkakuNumberToHankakuNumber(in <-chan KanaConverterRune) <-chan KanaConverterRune {
-	out := make(chan KanaConverterRune)
-	go func() {
-		defer close(out)
-		for r := range in {
-			if r.IsConverted {
-				out <- r
-				continue
-			}
-			if r.Rune >= '０' && r.Rune <= '９' {
-				out <- KanaConverterRune{Rune: r.Rune - 0xFEE0, IsConverted: true}
-			} else {
-				out <- r
-			}
-		}
-	}()
-	return out
+	return convertForKanaConverter(KanaConverterFunc(zenkakuNumberToHankakuNumber), in)
+}
+
+func zenkakuNumberToHankakuNumber(dst []KanaConverterRune, r KanaConverterRune) []KanaConverterRune {
+	if r.IsConverted {
+		return append(dst, r)
+	}
+	if r.Rune >= '０' && r.Rune <= '９' {
+		dst = append(dst, r.convert(r.Rune-0xFEE0))
+	} else {
+		dst = append(dst, r)
+	}
+	return dst
 }
 
 func HankakuEnglishNumberToZenkakuEnglishNumber(in <-chan KanaConverterRune) <-chan KanaConverterRune {
-	out := make(chan KanaConverterRune)
-	go func() {
-		defer close(out)
-		for r := range in {
-			if r.IsConverted {
-				out <- r
-				continue
-			}
-			switch {
-			case r.Rune == '\u0022', r.Rune == '\u0027', r.Rune == '\u005C', r.Rune == '\u007E':
-				out <- r
-			case r.Rune >= '\u0021' && r.Rune <= '\u007E':
-				out <- KanaConverterRune{Rune: r.Rune + 0xFEE0, IsConverted: true}
-			default:
-				out <- r
-			}
-		}
-	}()
-	return out
+	return convertForKanaConverter(KanaConverterFunc(hankakuEnglishNumberToZenkakuEnglishNumber), in)
+}
+
+func hankakuEnglishNumberToZenkakuEnglishNumber(dst []KanaConverterRune, r KanaConverterRune) []KanaConverterRune {
+	if r.IsConverted {
+		return append(dst, r)
+	}
+	switch {
+	case r.Rune == '\u0022', r.Rune == '\u0027', r.Rune == '\u005C', r.Rune == '\u007E':
+		dst = append(dst, r)
+	case r.Rune >= '\u0021' && r.Rune <= '\u007E':
+		dst = append(dst, r.convert(r.Rune+0xFEE0))
+	default:
+		dst = append(dst, r)
+	}
+	return dst
 }
 
 func ZenkakuEnglishNumberToHankakuEnglishNumber(in <-chan KanaConverterRune) <-chan KanaConverterRune {
-	out := make(chan KanaConverterRune)
-	go func() {
-		defer close(out)
-		for r := range in {
-			if r.IsConverted {
-				out <- r
-				continue
-			}
-			switch {
-			case r.Rune == '\uFF02', r.Rune == '\uFF07', r.Rune == '\uFF3C', r.Rune == '\uFF5E':
-				out <- r
-			case r.Rune >= '\uFF01' && r.Rune <= '\uFF5E':
-				out <- KanaConverterRune{Rune: r.Rune - 0xFEE0, IsConverted: true}
-			default:
-				out <- r
-			}
-		}
-	}()
-	return out
+	return convertForKanaConverter(KanaConverterFunc(zenkakuEnglishNumberToHankakuEnglishNumber), in)
+}
+
+func zenkakuEnglishNumberToHankakuEnglishNumber(dst []KanaConverterRune, r KanaConverterRune) []KanaConverterRune {
+	if r.IsConverted {
+		return append(dst, r)
+	}
+	switch {
+	case r.Rune == '\uFF02', r.Rune == '\uFF07', r.Rune == '\uFF3C', r.Rune == '\uFF5E':
+		dst = append(dst, r)
+	case r.Rune >= '\uFF01' && r.Rune <= '\uFF5E':
+		dst = append(dst, r.convert(r.Rune-0xFEE0))
+	default:
+		dst = append(dst, r)
+	}
+	return dst
 }
 
 func ZenkakuSpaceToHankakuSpace(in <-chan KanaConverterRune) <-chan KanaConverterRune {
-	out := make(chan KanaConverterRune)
-	go func() {
-		defer close(out)
-		for r := range in {
-			if r.IsConverted {
-				out <- r
-				continue
-			}
-			if r.Rune == '　' {
-				out <- KanaConverterRune{Rune: ' ', IsConverted: true}
-			} else {
-				out <- r
-			}
-		}
-	}()
-	return out
+	return convertForKanaConverter(KanaConverterFunc(zenkakuSpaceToHankakuSpace), in)
+}
+
+func zenkakuSpaceToHankakuSpace(dst []KanaConverterRune, r KanaConverterRune) []KanaConverterRune {
+	if r.IsConverted {
+		return append(dst, r)
+	}
+	if r.Rune == '　' {
+		dst = append(dst, r.convert(' '))
+	} else {
+		dst = append(dst, r)
+	}
+	return dst
 }
 
 func HankakuSpaceToZenkakuSpace(in <-chan KanaConverterRune) <-chan KanaConverterRune {
-	out := make(chan KanaConverterRune)
-	go func() {
-		defer close(out)
-		for r := range in {
-			if r.IsConverted {
-				out <- r
-				continue
-			}
-			if r.Rune == ' ' {
-				out <- KanaConverterRune{Rune: '　', IsConverted: true}
-			} else {
-				out <- r
-			}
-		}
-	}()
-	return out
+	return convertForKanaConverter(KanaConverterFunc(hankakuSpaceToZenkakuSpace), in)
+}
+
+func hankakuSpaceToZenkakuSpace(dst []KanaConverterRune, r KanaConverterRune) []KanaConverterRune {
+	if r.IsConverted {
+		return append(dst, r)
+	}
+	if r.Rune == ' ' {
+		dst = append(dst, r.convert('　'))
+	} else {
+		dst = append(dst, r)
+	}
+	return dst
 }
 
+// ZenkakuKatakanaToHankakuKatakana converts zenkaku katakana to hankaku.
+// Katakana without a hankaku form become the nearest one, such as ヰ to ｲ and ㇰ
+// to ｸ, and combining voiced marks become ﾞ and ﾟ. ヵ, ヶ and ヷ to ヺ are passed
+// through as mb_convert_kana does, and so are ゠, ヽ, ヾ and ヿ, which have no
+// hankaku form.
 func ZenkakuKatakanaToHankakuKatakana(in <-chan KanaConverterRune) <-chan KanaConverterRune {
-	out := make(chan KanaConverterRune)
-	go func() {
-		defer close(out)
-		for r := range in {
-			if r.IsConverted {
-				out <- r
-				continue
-			}
-			switch r.Rune {
-			case '、':
-				out <- KanaConverterRune{Rune: '､', IsConverted: true}
-			case '。':
-				out <- KanaConverterRune{Rune: '｡', IsConverted: true}
-			case '「':
-				out <- KanaConverterRune{Rune: '｢', IsConverted: true}
-			case '」':
-				out <- KanaConverterRune{Rune: '｣', IsConverted: true}
-			case '゛':
-				out <- KanaConverterRune{Rune: 'ﾞ', IsConverted: true}
-			case '゜':
-				out <- KanaConverterRune{Rune: 'ﾟ', IsConverted: true}
-			case 'ァ':
-				out <- KanaConverterRune{Rune: 'ｧ', IsConverted: true}
-			case 'ア':
-				out <- KanaConverterRune{Rune: 'ｱ', IsConverted: true}
-			case 'ィ':
-				out <- KanaConverterRune{Rune: 'ｨ', IsConverted: true}
-			case 'イ':
-				out <- KanaConverterRune{Rune: 'ｲ', IsConverted: true}
-			case 'ゥ':
-				out <- KanaConverterRune{Rune: 'ｩ', IsConverted: true}
-			case 'ウ':
-				out <- KanaConverterRune{Rune: 'ｳ', IsConverted: true}
-			case 'ェ':
-				out <- KanaConverterRune{Rune: 'ｪ', IsConverted: true}
-			case 'エ':
-				out <- KanaConverterRune{Rune: 'ｴ', IsConverted: true}
-			case 'ォ':
-				out <- KanaConverterRune{Rune: 'ｫ', IsConverted: true}
-			case 'オ':
-				out <- KanaConverterRune{Rune: 'ｵ', IsConverted: true}
-			case 'カ':
-				out <- KanaConverterRune{Rune: 'ｶ', IsConverted: true}
-			case 'ガ':
-				out <- KanaConverterRune{Rune: 'ｶ', IsConverted: true}
-				out <- KanaConverterRune{Rune: 'ﾞ', IsConverted: true}
-			case 'キ':
-				out <- KanaConverterRune{Rune: 'ｷ', IsConverted: true}
-			case 'ギ':
-				out <- KanaConverterRune{Rune: 'ｷ', IsConverted: true}
-				out <- KanaConverterRune{Rune: 'ﾞ', IsConverted: true}
-			case 'ク':
-				out <- KanaConverterRune{Rune: 'ｸ', IsConverted: true}
-			case 'グ':
-				out <- KanaConverterRune{Rune: 'ｸ', IsConverted: true}
-				out <- KanaConverterRune{Rune: 'ﾞ', IsConverted: true}
-			case 'ケ':
-				out <- KanaConverterRune{Rune: 'ｹ', IsConverted: true}
-			case 'ゲ':
-				out <- KanaConverterRune{Rune: 'ｹ', IsConverted: true}
-				out <- KanaConverterRune{Rune: 'ﾞ', IsConverted: true}
-			case 'コ':
-				out <- KanaConverterRune{Rune: 'ｺ', IsConverted: true}
-			case 'ゴ':
-				out <- KanaConverterRune{Rune: 'ｺ', IsConverted: true}
-				out <- KanaConverterRune{Rune: 'ﾞ', IsConverted: true}
-			case 'サ':
-				out <- KanaConverterRune{Rune: 'ｻ', IsConverted: true}
-			case 'ザ':
-				out <- KanaConverterRune{Rune: 'ｻ', IsConverted: true}
-				out <- KanaConverterRune{Rune: 'ﾞ', IsConverted: true}
-			case 'シ':
-				out <- KanaConverterRune{Rune: 'ｼ', IsConverted: true}
-			case 'ジ':
-				out <- KanaConverterRune{Rune: 'ｼ', IsConverted: true}
-				out <- KanaConverterRune{Rune: 'ﾞ', IsConverted: true}
-			case 'ス':
-				out <- KanaConverterRune{Rune: 'ｽ', IsConverted: true}
-			case 'ズ':
-				out <- KanaConverterRune{Rune: 'ｽ', IsConverted: true}
-				out <- KanaConverterRune{Rune: 'ﾞ', IsConverted: true}
-			case 'セ':
-				out <- KanaConverterRune{Rune: 'ｾ', IsConverted: true}
-			case 'ゼ':
-				out <- KanaConverterRune{Rune: 'ｾ', IsConverted: true}
-				out <- KanaConverterRune{Rune: 'ﾞ', IsConverted: true}
-			case 'ソ':
-				out <- KanaConverterRune{Rune: 'ｿ', IsConverted: true}
-			case 'ゾ':
-				out <- KanaConverterRune{Rune: 'ｿ', IsConverted: true}
-				out <- KanaConverterRune{Rune: 'ﾞ', IsConverted: true}
-			case 'タ':
-				out <- KanaConverterRune{Rune: 'ﾀ', IsConverted: true}
-			case 'ダ':
-				out <- KanaConverterRune{Rune: 'ﾀ', IsConverted: true}
-				out <- KanaConverterRune{Rune: 'ﾞ', IsConverted: true}
-			case 'チ':
-				out <- KanaConverterRune{Rune: 'ﾁ', IsConverted: true}
-			case 'ヂ':
-				out <- KanaConverterRune{Rune: 'ﾁ', IsConverted: true}
-				out <- KanaConverterRune{Rune: 'ﾞ', IsConverted: true}
-			case 'ッ':
-				out <- KanaConverterRune{Rune: 'ｯ', IsConverted: true}
-			case 'ツ':
-				out <- KanaConverterRune{Rune: 'ﾂ', IsConverted: true}
-			case 'ヅ':
-				out <- KanaConverterRune{Rune: 'ﾂ', IsConverted: true}
-				out <- KanaConverterRune{Rune: 'ﾞ', IsConverted: true}
-			case 'テ':
-				out <- KanaConverterRune{Rune: 'ﾃ', IsConverted: true}
-			case 'デ':
-				out <- KanaConverterRune{Rune: 'ﾃ', IsConverted: true}
-				out <- KanaConverterRune{Rune: 'ﾞ', IsConverted: true}
-			case 'ト':
-				out <- KanaConverterRune{Rune: 'ﾄ', IsConverted: true}
-			case 'ド':
-				out <- KanaConverterRune{Rune: 'ﾄ', IsConverted: true}
-				out <- KanaConverterRune{Rune: 'ﾞ', IsConverted: true}
-			case 'ナ':
-				out <- KanaConverterRune{Rune: 'ﾅ', IsConverted: true}
-			case 'ニ':
-				out <- KanaConverterRune{Rune: 'ﾆ', IsConverted: true}
-			case 'ヌ':
-				out <- KanaConverterRune{Rune: 'ﾇ', IsConverted: true}
-			case 'ネ':
-				out <- KanaConverterRune{Rune: 'ﾈ', IsConverted: true}
-			case 'ノ':
-				out <- KanaConverterRune{Rune: 'ﾉ', IsConverted: true}
-			case 'ハ':
-				out <- KanaConverterRune{Rune: 'ﾊ', IsConverted: true}
-			case 'バ':
-				out <- KanaConverterRune{Rune: 'ﾊ', IsConverted: true}
-				out <- KanaConverterRune{Rune: 'ﾞ', IsConverted: true}
-			case 'パ':
-				out <- KanaConverterRune{Rune: 'ﾊ', IsConverted: true}
-				out <- KanaConverterRune{Rune: 'ﾟ', IsConverted: true}
-			case 'ヒ':
-				out <- KanaConverterRune{Rune: 'ﾋ', IsConverted: true}
-			case 'ビ':
-				out <- KanaConverterRune{Rune: 'ﾋ', IsConverted: true}
-				out <- KanaConverterRune{Rune: 'ﾞ', IsConverted: true}
-			case 'ピ':
-				out <- KanaConverterRune{Rune: 'ﾋ', IsConverted: true}
-				out <- KanaConverterRune{Rune: 'ﾟ', IsConverted: true}
-			case 'フ':
-				out <- KanaConverterRune{Rune: 'ﾌ', IsConverted: true}
-			case 'ブ':
-				out <- KanaConverterRune{Rune: 'ﾌ', IsConverted: true}
-				out <- KanaConverterRune{Rune: 'ﾞ', IsConverted: true}
-			case 'プ':
-				out <- KanaConverterRune{Rune: 'ﾌ', IsConverted: true}
-				out <- KanaConverterRune{Rune: 'ﾟ', IsConverted: true}
-			case 'ヘ':
-				out <- KanaConverterRune{Rune: 'ﾍ', IsConverted: true}
-			case 'ベ':
-				out <- KanaConverterRune{Rune: 'ﾍ', IsConverted: true}
-				out <- KanaConverterRune{Rune: 'ﾞ', IsConverted: true}
-			case 'ペ':
-				out <- KanaConverterRune{Rune: 'ﾍ', IsConverted: true}
-				out <- KanaConverterRune{Rune: 'ﾟ', IsConverted: true}
-			case 'ホ':
-				out <- KanaConverterRune{Rune: 'ﾎ', IsConverted: true}
-			case 'ボ':
-				out <- KanaConverterRune{Rune: 'ﾎ', IsConverted: true}
-				out <- KanaConverterRune{Rune: 'ﾞ', IsConverted: true}
-			case 'ポ':
-				out <- KanaConverterRune{Rune: 'ﾎ', IsConverted: true}
-				out <- KanaConverterRune{Rune: 'ﾟ', IsConverted: true}
-			case 'マ':
-				out <- KanaConverterRune{Rune: 'ﾏ', IsConverted: true}
-			case 'ミ':
-				out <- KanaConverterRune{Rune: 'ﾐ', IsConverted: true}
-			case 'ム':
-				out <- KanaConverterRune{Rune: 'ﾑ', IsConverted: true}
-			case 'メ':
-				out <- KanaConverterRune{Rune: 'ﾒ', IsConverted: true}
-			case 'モ':
-				out <- KanaConverterRune{Rune: 'ﾓ', IsConverted: true}
-			case 'ャ':
-				out <- KanaConverterRune{Rune: 'ｬ', IsConverted: true}
-			case 'ヤ':
-				out <- KanaConverterRune{Rune: 'ﾔ', IsConverted: true}
-			case 'ュ':
-				out <- KanaConverterRune{Rune: 'ｭ', IsConverted: true}
-			case 'ユ':
-				out <- KanaConverterRune{Rune: 'ﾕ', IsConverted: true}
-			case 'ョ':
-				out <- KanaConverterRune{Rune: 'ｮ', IsConverted: true}
-			case 'ヨ':
-				out <- KanaConverterRune{Rune: 'ﾖ', IsConverted: true}
-			case 'ラ':
-				out <- KanaConverterRune{Rune: 'ﾗ', IsConverted: true}
-			case 'リ':
-				out <- KanaConverterRune{Rune: 'ﾘ', IsConverted: true}
-			case 'ル':
-				out <- KanaConverterRune{Rune: 'ﾙ', IsConverted: true}
-			case 'レ':
-				out <- KanaConverterRune{Rune: 'ﾚ', IsConverted: true}
-			case 'ロ':
-				out <- KanaConverterRune{Rune: 'ﾛ', IsConverted: true}
-			case 'ヮ':
-				out <- KanaConverterRune{Rune: 'ﾜ', IsConverted: true}
-			case 'ワ':
-				out <- KanaConverterRune{Rune: 'ﾜ', IsConverted: true}
-			case 'ヰ':
-				out <- KanaConverterRune{Rune: 'ｲ', IsConverted: true}
-			case 'ヱ':
-				out <- KanaConverterRune{Rune: 'ｴ', IsConverted: true}
-			case 'ヲ':
-				out <- KanaConverterRune{Rune: 'ｦ', IsConverted: true}
-			case 'ン':
-				out <- KanaConverterRune{Rune: 'ﾝ', IsConverted: true}
-			case 'ヴ':
-				out <- KanaConverterRune{Rune: 'ｳ', IsConverted: true}
-				out <- KanaConverterRune{Rune: 'ﾞ', IsConverted: true}
-			case '・':
-				out <- KanaConverterRune{Rune: '･', IsConverted: true}
-			case 'ー':
-				out <- KanaConverterRune{Rune: 'ｰ', IsConverted: true}
-			default:
-				out <- r
-			}
-		}
-	}()
-	return out
+	return convertForKanaConverter(KanaConverterFunc(zenkakuKatakanaToHankakuKatakana), in)
 }
 
-func hankakuKatakanaToZenkakuKatakanaSimple(in rune) KanaConverterRune {
-	switch in {
+func zenkakuKatakanaToHankakuKatakana(dst []KanaConverterRune, r KanaConverterRune) []KanaConverterRune {
+	if r.IsConverted {
+		return append(dst, r)
+	}
+	switch r.Rune {
+	case '、':
+		dst = append(dst, r.convert('､'))
+	case '。':
+		dst = append(dst, r.convert('｡'))
+	case '「':
+		dst = append(dst, r.convert('｢'))
+	case '」':
+		dst = append(dst, r.convert('｣'))
+	case '゛', '\u3099':
+		dst = append(dst, r.convert('ﾞ'))
+	case '゜', '\u309a':
+		dst = append(dst, r.convert('ﾟ'))
+	case 'ァ':
+		dst = append(dst, r.convert('ｧ'))
+	case 'ア':
+		dst = append(dst, r.convert('ｱ'))
+	case 'ィ':
+		dst = append(dst, r.convert('ｨ'))
+	case 'イ':
+		dst = append(dst, r.convert('ｲ'))
+	case 'ゥ':
+		dst = append(dst, r.convert('ｩ'))
+	case 'ウ':
+		dst = append(dst, r.convert('ｳ'))
+	case 'ェ':
+		dst = append(dst, r.convert('ｪ'))
+	case 'エ':
+		dst = append(dst, r.convert('ｴ'))
+	case 'ォ':
+		dst = append(dst, r.convert('ｫ'))
+	case 'オ':
+		dst = append(dst, r.convert('ｵ'))
+	case 'カ':
+		dst = append(dst, r.convert('ｶ'))
+	case 'ガ':
+		dst = append(dst, r.convert('ｶ'))
+		dst = append(dst, r.convert('ﾞ'))
+	case 'キ':
+		dst = append(dst, r.convert('ｷ'))
+	case 'ギ':
+		dst = append(dst, r.convert('ｷ'))
+		dst = append(dst, r.convert('ﾞ'))
+	case 'ク':
+		dst = append(dst, r.convert('ｸ'))
+	case 'グ':
+		dst = append(dst, r.convert('ｸ'))
+		dst = append(dst, r.convert('ﾞ'))
+	case 'ケ':
+		dst = append(dst, r.convert('ｹ'))
+	case 'ゲ':
+		dst = append(dst, r.convert('ｹ'))
+		dst = append(dst, r.convert('ﾞ'))
+	case 'コ':
+		dst = append(dst, r.convert('ｺ'))
+	case 'ゴ':
+		dst = append(dst, r.convert('ｺ'))
+		dst = append(dst, r.convert('ﾞ'))
+	case 'サ':
+		dst = append(dst, r.convert('ｻ'))
+	case 'ザ':
+		dst = append(dst, r.convert('ｻ'))
+		dst = append(dst, r.convert('ﾞ'))
+	case 'シ':
+		dst = append(dst, r.convert('ｼ'))
+	case 'ジ':
+		dst = append(dst, r.convert('ｼ'))
+		dst = append(dst, r.convert('ﾞ'))
+	case 'ス':
+		dst = append(dst, r.convert('ｽ'))
+	case 'ズ':
+		dst = append(dst, r.convert('ｽ'))
+		dst = append(dst, r.convert('ﾞ'))
+	case 'セ':
+		dst = append(dst, r.convert('ｾ'))
+	case 'ゼ':
+		dst = append(dst, r.convert('ｾ'))
+		dst = append(dst, r.convert('ﾞ'))
+	case 'ソ':
+		dst = append(dst, r.convert('ｿ'))
+	case 'ゾ':
+		dst = append(dst, r.convert('ｿ'))
+		dst = append(dst, r.convert('ﾞ'))
+	case 'タ':
+		dst = append(dst, r.convert('ﾀ'))
+	case 'ダ':
+		dst = append(dst, r.convert('ﾀ'))
+		dst = append(dst, r.convert('ﾞ'))
+	case 'チ':
+		dst = append(dst, r.convert('ﾁ'))
+	case 'ヂ':
+		dst = append(dst, r.convert('ﾁ'))
+		dst = append(dst, r.convert('ﾞ'))
+	case 'ッ':
+		dst = append(dst, r.convert('ｯ'))
+	case 'ツ':
+		dst = append(dst, r.convert('ﾂ'))
+	case 'ヅ':
+		dst = append(dst, r.convert('ﾂ'))
+		dst = append(dst, r.convert('ﾞ'))
+	case 'テ':
+		dst = append(dst, r.convert('ﾃ'))
+	case 'デ':
+		dst = append(dst, r.convert('ﾃ'))
+		dst = append(dst, r.convert('ﾞ'))
+	case 'ト':
+		dst = append(dst, r.convert('ﾄ'))
+	case 'ド':
+		dst = append(dst, r.convert('ﾄ'))
+		dst = append(dst, r.convert('ﾞ'))
+	case 'ナ':
+		dst = append(dst, r.convert('ﾅ'))
+	case 'ニ':
+		dst = append(dst, r.convert('ﾆ'))
+	case 'ヌ':
+		dst = append(dst, r.convert('ﾇ'))
+	case 'ネ':
+		dst = append(dst, r.convert('ﾈ'))
+	case 'ノ':
+		dst = append(dst, r.convert('ﾉ'))
+	case 'ハ':
+		dst = append(dst, r.convert('ﾊ'))
+	case 'バ':
+		dst = append(dst, r.convert('ﾊ'))
+		dst = append(dst, r.convert('ﾞ'))
+	case 'パ':
+		dst = append(dst, r.convert('ﾊ'))
+		dst = append(dst, r.convert('ﾟ'))
+	case 'ヒ':
+		dst = append(dst, r.convert('ﾋ'))
+	case 'ビ':
+		dst = append(dst, r.convert('ﾋ'))
+		dst = append(dst, r.convert('ﾞ'))
+	case 'ピ':
+		dst = append(dst, r.convert('ﾋ'))
+		dst = append(dst, r.convert('ﾟ'))
+	case 'フ':
+		dst = append(dst, r.convert('ﾌ'))
+	case 'ブ':
+		dst = append(dst, r.convert('ﾌ'))
+		dst = append(dst, r.convert('ﾞ'))
+	case 'プ':
+		dst = append(dst, r.convert('ﾌ'))
+		dst = append(dst, r.convert('ﾟ'))
+	case 'ヘ':
+		dst = append(dst, r.convert('ﾍ'))
+	case 'ベ':
+		dst = append(dst, r.convert('ﾍ'))
+		dst = append(dst, r.convert('ﾞ'))
+	case 'ペ':
+		dst = append(dst, r.convert('ﾍ'))
+		dst = append(dst, r.convert('ﾟ'))
+	case 'ホ':
+		dst = append(dst, r.convert('ﾎ'))
+	case 'ボ':
+		dst = append(dst, r.convert('ﾎ'))
+		dst = append(dst, r.convert('ﾞ'))
+	case 'ポ':
+		dst = append(dst, r.convert('ﾎ'))
+		dst = append(dst, r.convert('ﾟ'))
+	case 'マ':
+		dst = append(dst, r.convert('ﾏ'))
+	case 'ミ':
+		dst = append(dst, r.convert('ﾐ'))
+	case 'ム':
+		dst = append(dst, r.convert('ﾑ'))
+	case 'メ':
+		dst = append(dst, r.convert('ﾒ'))
+	case 'モ':
+		dst = append(dst, r.convert('ﾓ'))
+	case 'ャ':
+		dst = append(dst, r.convert('ｬ'))
+	case 'ヤ':
+		dst = append(dst, r.convert('ﾔ'))
+	case 'ュ':
+		dst = append(dst, r.convert('ｭ'))
+	case 'ユ':
+		dst = append(dst, r.convert('ﾕ'))
+	case 'ョ':
+		dst = append(dst, r.convert('ｮ'))
+	case 'ヨ':
+		dst = append(dst, r.convert('ﾖ'))
+	case 'ラ':
+		dst = append(dst, r.convert('ﾗ'))
+	case 'リ':
+		dst = append(dst, r.convert('ﾘ'))
+	case 'ル':
+		dst = append(dst, r.convert('ﾙ'))
+	case 'レ':
+		dst = append(dst, r.convert('ﾚ'))
+	case 'ロ':
+		dst = append(dst, r.convert('ﾛ'))
+	case 'ヮ':
+		dst = append(dst, r.convert('ﾜ'))
+	case 'ワ':
+		dst = append(dst, r.convert('ﾜ'))
+	case 'ヰ':
+		dst = append(dst, r.convert('ｲ'))
+	case 'ヱ':
+		dst = append(dst, r.convert('ｴ'))
+	case 'ヲ':
+		dst = append(dst, r.convert('ｦ'))
+	case 'ン':
+		dst = append(dst, r.convert('ﾝ'))
+	case 'ヴ':
+		dst = append(dst, r.convert('ｳ'))
+		dst = append(dst, r.convert('ﾞ'))
+	case 'ㇰ':
+		dst = append(dst, r.convert('ｸ'))
+	case 'ㇱ':
+		dst = append(dst, r.convert('ｼ'))
+	case 'ㇲ':
+		dst = append(dst, r.convert('ｽ'))
+	case 'ㇳ':
+		dst = append(dst, r.convert('ﾄ'))
+	case 'ㇴ':
+		dst = append(dst, r.convert('ﾇ'))
+	case 'ㇵ':
+		dst = append(dst, r.convert('ﾊ'))
+	case 'ㇶ':
+		dst = append(dst, r.convert('ﾋ'))
+	case 'ㇷ':
+		dst = append(dst, r.convert('ﾌ'))
+	case 'ㇸ':
+		dst = append(dst, r.convert('ﾍ'))
+	case 'ㇹ':
+		dst = append(dst, r.convert('ﾎ'))
+	case 'ㇺ':
+		dst = append(dst, r.convert('ﾑ'))
+	case 'ㇻ':
+		dst = append(dst, r.convert('ﾗ'))
+	case 'ㇼ':
+		dst = append(dst, r.convert('ﾘ'))
+	case 'ㇽ':
+		dst = append(dst, r.convert('ﾙ'))
+	case 'ㇾ':
+		dst = append(dst, r.convert('ﾚ'))
+	case 'ㇿ':
+		dst = append(dst, r.convert('ﾛ'))
+	case '・':
+		dst = append(dst, r.convert('･'))
+	case 'ー':
+		dst = append(dst, r.convert('ｰ'))
+	default:
+		dst = append(dst, r)
+	}
+	return dst
+}
+
+func hankakuKatakanaToZenkakuKatakanaSimple(r KanaConverterRune) KanaConverterRune {
+	switch r.Rune {
 	case '｡':
-		return KanaConverterRune{Rune: '。', IsConverted: true}
+		return r.convert('。')
 	case '｢':
-		return KanaConverterRune{Rune: '「', IsConverted: true}
+		return r.convert('「')
 	case '｣':
-		return KanaConverterRune{Rune: '」', IsConverted: true}
+		return r.convert('」')
 	case '､':
-		return KanaConverterRune{Rune: '、', IsConverted: true}
+		return r.convert('、')
 	case '･':
-		return KanaConverterRune{Rune: '・', IsConverted: true}
+		return r.convert('・')
 	case 'ｦ':
-		return KanaConverterRune{Rune: 'ヲ', IsConverted: true}
+		return r.convert('ヲ')
 	case 'ｧ':
-		return KanaConverterRune{Rune: 'ァ', IsConverted: true}
+		return r.convert('ァ')
 	case 'ｨ':
-		return KanaConverterRune{Rune: 'ィ', IsConverted: true}
+		return r.convert('ィ')
 	case 'ｩ':
-		return KanaConverterRune{Rune: 'ゥ', IsConverted: true}
+		return r.convert('ゥ')
 	case 'ｪ':
-		return KanaConverterRune{Rune: 'ェ', IsConverted: true}
+		return r.convert('ェ')
 	case 'ｫ':
-		return KanaConverterRune{Rune: 'ォ', IsConverted: true}
+		return r.convert('ォ')
 	case 'ｬ':
-		return KanaConverterRune{Rune: 'ャ', IsConverted: true}
+		return r.convert('ャ')
 	case 'ｭ':
-		return KanaConverterRune{Rune: 'ュ', IsConverted: true}
+		return r.convert('ュ')
 	case 'ｮ':
-		return KanaConverterRune{Rune: 'ョ', IsConverted: true}
+		return r.convert('ョ')
 	case 'ｯ':
-		return KanaConverterRune{Rune: 'ッ', IsConverted: true}
+		return r.convert('ッ')
 	case 'ｰ':
-		return KanaConverterRune{Rune: 'ー', IsConverted: true}
+		return r.convert('ー')
 	case 'ｱ':
-		return KanaConverterRune{Rune: 'ア', IsConverted: true}
+		return r.convert('ア')
 	case 'ｲ':
-		return KanaConverterRune{Rune: 'イ', IsConverted: true}
+		return r.convert('イ')
 	case 'ｳ':
-		return KanaConverterRune{Rune: 'ウ', IsConverted: true}
+		return r.convert('ウ')
 	case 'ｴ':
-		return KanaConverterRune{Rune: 'エ', IsConverted: true}
+		return r.convert('エ')
 	case 'ｵ':
-		return KanaConverterRune{Rune: 'オ', IsConverted: true}
+		return r.convert('オ')
 	case 'ｶ':
-		return KanaConverterRune{Rune: 'カ', IsConverted: true}
+		return r.convert('カ')
 	case 'ｷ':
-		return KanaConverterRune{Rune: 'キ', IsConverted: true}
+		return r.convert('キ')
 	case 'ｸ':
-		return KanaConverterRune{Rune: 'ク', IsConverted: true}
+		return r.convert('ク')
 	case 'ｹ':
-		return KanaConverterRune{Rune: 'ケ', IsConverted: true}
+		return r.convert('ケ')
 	case 'ｺ':
-		return KanaConverterRune{Rune: 'コ', IsConverted: true}
+		return r.convert('コ')
 	case 'ｻ':
-		return KanaConverterRune{Rune: 'サ', IsConverted: true}
+		return r.convert('サ')
 	case 'ｼ':
-		return KanaConverterRune{Rune: 'シ', IsConverted: true}
+		return r.convert('シ')
 	case 'ｽ':
-		return KanaConverterRune{Rune: 'ス', IsConverted: true}
+		return r.convert('ス')
 	case 'ｾ':
-		return KanaConverterRune{Rune: 'セ', IsConverted: true}
+		return r.convert('セ')
 	case 'ｿ':
-		return KanaConverterRune{Rune: 'ソ', IsConverted: true}
+		return r.convert('ソ')
 	case 'ﾀ':
-		return KanaConverterRune{Rune: 'タ', IsConverted: true}
+		return r.convert('タ')
 	case 'ﾁ':
-		return KanaConverterRune{Rune: 'チ', IsConverted: true}
+		return r.convert('チ')
 	case 'ﾂ':
-		return KanaConverterRune{Rune: 'ツ', IsConverted: true}
+		return r.convert('ツ')
 	case 'ﾃ':
-		return KanaConverterRune{Rune: 'テ', IsConverted: true}
+		return r.convert('テ')
 	case 'ﾄ':
-		return KanaConverterRune{Rune: 'ト', IsConverted: true}
+		return r.convert('ト')
 	case 'ﾅ':
-		return KanaConverterRune{Rune: 'ナ', IsConverted: true}
+		return r.convert('ナ')
 	case 'ﾆ':
-		return KanaConverterRune{Rune: 'ニ', IsConverted: true}
+		return r.convert('ニ')
 	case 'ﾇ':
-		return KanaConverterRune{Rune: 'ヌ', IsConverted: true}
+		return r.convert('ヌ')
 	case 'ﾈ':
-		return KanaConverterRune{Rune: 'ネ', IsConverted: true}
+		return r.convert('ネ')
 	case 'ﾉ':
-		return KanaConverterRune{Rune: 'ノ', IsConverted: true}
+		return r.convert('ノ')
 	case 'ﾊ':
-		return KanaConverterRune{Rune: 'ハ', IsConverted: true}
+		return r.convert('ハ')
 	case 'ﾋ':
-		return KanaConverterRune{Rune: 'ヒ', IsConverted: true}
+		return r.convert('ヒ')
 	case 'ﾌ':
-		return KanaConverterRune{Rune: 'フ', IsConverted: true}
+		return r.convert('フ')
 	case 'ﾍ':
-		return KanaConverterRune{Rune: 'ヘ', IsConverted: true}
+		return r.convert('ヘ')
 	case 'ﾎ':
-		return KanaConverterRune{Rune: 'ホ', IsConverted: true}
+		return r.convert('ホ')
 	case 'ﾏ':
-		return KanaConverterRune{Rune: 'マ', IsConverted: true}
+		return r.convert('マ')
 	case 'ﾐ':
-		return KanaConverterRune{Rune: 'ミ', IsConverted: true}
+		return r.convert('ミ')
 	case 'ﾑ':
-		return KanaConverterRune{Rune: 'ム', IsConverted: true}
+		return r.convert('ム')
 	case 'ﾒ':
-		return KanaConverterRune{Rune: 'メ', IsConverted: true}
+		return r.convert('メ')
 	case 'ﾓ':
-		return KanaConverterRune{Rune: 'モ', IsConverted: true}
+		return r.convert('モ')
 	case 'ﾔ':
-		return KanaConverterRune{Rune: 'ヤ', IsConverted: true}
+		return r.convert('ヤ')
 	case 'ﾕ':
-		return KanaConverterRune{Rune: 'ユ', IsConverted: true}
+		return r.convert('ユ')
 	case 'ﾖ':
-		return KanaConverterRune{Rune: 'ヨ', IsConverted: true}
+		return r.convert('ヨ')
 	case 'ﾗ':
-		return KanaConverterRune{Rune: 'ラ', IsConverted: true}
+		return r.convert('ラ')
 	case 'ﾘ':
-		return KanaConverterRune{Rune: 'リ', IsConverted: true}
+		return r.convert('リ')
 	case 'ﾙ':
-		return KanaConverterRune{Rune: 'ル', IsConverted: true}
+		return r.convert('ル')
 	case 'ﾚ':
-		return KanaConverterRune{Rune: 'レ', IsConverted: true}
+		return r.convert('レ')
 	case 'ﾛ':
-		return KanaConverterRune{Rune: 'ロ', IsConverted: true}
+		return r.convert('ロ')
 	case 'ﾜ':
-		return KanaConverterRune{Rune: 'ワ', IsConverted: true}
+		return r.convert('ワ')
 	case 'ﾝ':
-		return KanaConverterRune{Rune: 'ン', IsConverted: true}
+		return r.convert('ン')
 	case 'ﾞ':
-		return KanaConverterRune{Rune: '゛', IsConverted: true}
+		return r.convert('゛')
 	case 'ﾟ':
-		return KanaConverterRune{Rune: '゜', IsConverted: true}
+		return r.convert('゜')
 	default:
-		return KanaConverterRune{Rune: in}
+		return r
 	}
 }
 
+// HankakuKatakanaToZenkakuKatakana converts hankaku katakana to zenkaku. With
+// v, a base followed by ﾞ or ﾟ is composed, such as ﾜﾞ to ヷ and ｦﾞ to ヺ. ｲﾞ
+// and ｴﾞ are not composed to ヸ and ヹ because ｲ and ｴ are イ and エ.
 func HankakuKatakanaToZenkakuKatakana(in <-chan KanaConverterRune, v bool) <-chan KanaConverterRune {
-	out := make(chan KanaConverterRune)
-	go func() {
-		defer close(out)
-		if v {
-			var before *rune
-			for r := range in {
-				if r.IsConverted {
-					out <- r
-					continue
-				}
-				switch r.Rune {
-				case 'ｳ', 'ｶ', 'ｷ', 'ｸ', 'ｹ', 'ｺ', 'ｻ', 'ｼ', 'ｽ', 'ｾ', 'ｿ', 'ﾀ', 'ﾁ', 'ﾂ', 'ﾃ', 'ﾄ', 'ﾊ', 'ﾋ', 'ﾌ', 'ﾍ', 'ﾎ':
-					if before != nil {
-						out <- hankakuKatakanaToZenkakuKatakanaSimple(*before)
-					}
-					r := r
-					before = &r.Rune
-				case 'ﾞ':
-					if before == nil {
-						out <- KanaConverterRune{Rune: '゛', IsConverted: true}
-					} else {
-						switch *before {
-						case 'ｳ':
-							out <- KanaConverterRune{Rune: 'ヴ', IsConverted: true}
-						case 'ｶ':
-							out <- KanaConverterRune{Rune: 'ガ', IsConverted: true}
-						case 'ｷ':
-							out <- KanaConverterRune{Rune: 'ギ', IsConverted: true}
-						case 'ｸ':
-							out <- KanaConverterRune{Rune: 'グ', IsConverted: true}
-						case 'ｹ':
-							out <- KanaConverterRune{Rune: 'ゲ', IsConverted: true}
-						case 'ｺ':
-							out <- KanaConverterRune{Rune: 'ゴ', IsConverted: true}
-						case 'ｻ':
-							out <- KanaConverterRune{Rune: 'ザ', IsConverted: true}
-						case 'ｼ':
-							out <- KanaConverterRune{Rune: 'ジ', IsConverted: true}
-						case 'ｽ':
-							out <- KanaConverterRune{Rune: 'ズ', IsConverted: true}
-						case 'ｾ':
-							out <- KanaConverterRune{Rune: 'ゼ', IsConverted: true}
-						case 'ｿ':
-							out <- KanaConverterRune{Rune: 'ゾ', IsConverted: true}
-						case 'ﾀ':
-							out <- KanaConverterRune{Rune: 'ダ', IsConverted: true}
-						case 'ﾁ':
-							out <- KanaConverterRune{Rune: 'ヂ', IsConverted: true}
-						case 'ﾂ':
-							out <- KanaConverterRune{Rune: 'ヅ', IsConverted: true}
-						case 'ﾃ':
-							out <- KanaConverterRune{Rune: 'デ', IsConverted: true}
-						case 'ﾄ':
-							out <- KanaConverterRune{Rune: 'ド', IsConverted: true}
-						case 'ﾊ':
-							out <- KanaConverterRune{Rune: 'バ', IsConverted: true}
-						case 'ﾋ':
-							out <- KanaConverterRune{Rune: 'ビ', IsConverted: true}
-						case 'ﾌ':
-							out <- KanaConverterRune{Rune: 'ブ', IsConverted: true}
-						case 'ﾍ':
-							out <- KanaConverterRune{Rune: 'ベ', IsConverted: true}
-						case 'ﾎ':
-							out <- KanaConverterRune{Rune: 'ボ', IsConverted: true}
-						default:
-							out <- hankakuKatakanaToZenkakuKatakanaSimple(*before)
-							out <- KanaConverterRune{Rune: '゛', IsConverted: true}
-						}
-						before = nil
-					}
-				case 'ﾟ':
-					if before == nil {
-						out <- KanaConverterRune{Rune: '゜', IsConverted: true}
-					} else {
-						switch *before {
-						case 'ﾊ':
-							out <- KanaConverterRune{Rune: 'パ', IsConverted: true}
-						case 'ﾋ':
-							out <- KanaConverterRune{Rune: 'ピ', IsConverted: true}
-						case 'ﾌ':
-							out <- KanaConverterRune{Rune: 'プ', IsConverted: true}
-						case 'ﾍ':
-							out <- KanaConverterRune{Rune: 'ペ', IsConverted: true}
-						case 'ﾎ':
-							out <- KanaConverterRune{Rune: 'ポ', IsConverted: true}
-						default:
-							out <- hankakuKatakanaToZenkakuKatakanaSimple(*before)
-							out <- KanaConverterRune{Rune: '゜', IsConverted: true}
-						}
-						before = nil
-					}
-				default:
-					if before != nil {
-						out <- hankakuKatakanaToZenkakuKatakanaSimple(*before)
-						before = nil
-					}
-					out <- hankakuKatakanaToZenkakuKatakanaSimple(r.Rune)
-				}
-			}
-			if before != nil {
-				out <- hankakuKatakanaToZenkakuKatakanaSimple(*before)
+	return convertForKanaConverter(&hankakuKatakanaToZenkakuKatakanaStage{v: v}, in)
+}
+
+type hankakuKatakanaToZenkakuKatakanaStage struct {
+	v      bool
+	before *KanaConverterRune
+}
+
+func (s *hankakuKatakanaToZenkakuKatakanaStage) Push(dst []KanaConverterRune, r KanaConverterRune) []KanaConverterRune {
+	if r.IsConverted {
+		// a base held is not composed with a converted rune, and comes before it
+		return append(s.Flush(dst), r)
+	}
+	if !s.v {
+		return append(dst, hankakuKatakanaToZenkakuKatakanaSimple(r))
+	}
+	switch r.Rune {
+	case 'ｳ', 'ｶ', 'ｷ', 'ｸ', 'ｹ', 'ｺ', 'ｻ', 'ｼ', 'ｽ', 'ｾ', 'ｿ', 'ﾀ', 'ﾁ', 'ﾂ', 'ﾃ', 'ﾄ', 'ﾊ', 'ﾋ', 'ﾌ', 'ﾍ', 'ﾎ', 'ﾜ', 'ｦ':
+		if s.before != nil {
+			dst = append(dst, hankakuKatakanaToZenkakuKatakanaSimple(*s.before))
+		}
+		r := r
+		s.before = &r
+	case 'ﾞ':
+		if s.before == nil {
+			dst = append(dst, r.convert('゛'))
+		} else {
+			switch s.before.Rune {
+			case 'ｳ':
+				dst = append(dst, s.before.compose(r, 'ヴ'))
+			case 'ｶ':
+				dst = append(dst, s.before.compose(r, 'ガ'))
+			case 'ｷ':
+				dst = append(dst, s.before.compose(r, 'ギ'))
+			case 'ｸ':
+				dst = append(dst, s.before.compose(r, 'グ'))
+			case 'ｹ':
+				dst = append(dst, s.before.compose(r, 'ゲ'))
+			case 'ｺ':
+				dst = append(dst, s.before.compose(r, 'ゴ'))
+			case 'ｻ':
+				dst = append(dst, s.before.compose(r, 'ザ'))
+			case 'ｼ':
+				dst = append(dst, s.before.compose(r, 'ジ'))
+			case 'ｽ':
+				dst = append(dst, s.before.compose(r, 'ズ'))
+			case 'ｾ':
+				dst = append(dst, s.before.compose(r, 'ゼ'))
+			case 'ｿ':
+				dst = append(dst, s.before.compose(r, 'ゾ'))
+			case 'ﾀ':
+				dst = append(dst, s.before.compose(r, 'ダ'))
+			case 'ﾁ':
+				dst = append(dst, s.before.compose(r, 'ヂ'))
+			case 'ﾂ':
+				dst = append(dst, s.before.compose(r, 'ヅ'))
+			case 'ﾃ':
+				dst = append(dst, s.before.compose(r, 'デ'))
+			case 'ﾄ':
+				dst = append(dst, s.before.compose(r, 'ド'))
+			case 'ﾊ':
+				dst = append(dst, s.before.compose(r, 'バ'))
+			case 'ﾋ':
+				dst = append(dst, s.before.compose(r, 'ビ'))
+			case 'ﾌ':
+				dst = append(dst, s.before.compose(r, 'ブ'))
+			case 'ﾍ':
+				dst = append(dst, s.before.compose(r, 'ベ'))
+			case 'ﾎ':
+				dst = append(dst, s.before.compose(r, 'ボ'))
+			case 'ﾜ':
+				dst = append(dst, s.before.compose(r, 'ヷ'))
+			case 'ｦ':
+				dst = append(dst, s.before.compose(r, 'ヺ'))
+			default:
+				dst = append(dst, hankakuKatakanaToZenkakuKatakanaSimple(*s.before))
+				dst = append(dst, r.convert('゛'))
 			}
+			s.before = nil
+		}
+	case 'ﾟ':
+		if s.before == nil {
+			dst = append(dst, r.convert('゜'))
 		} else {
-			for r := range in {
-				if r.IsConverted {
-					out <- r
-					continue
-				}
-				out <- hankakuKatakanaToZenkakuKatakanaSimple(r.Rune)
+			switch s.before.Rune {
+			case 'ﾊ':
+				dst = append(dst, s.before.compose(r, 'パ'))
+			case 'ﾋ':
+				dst = append(dst, s.before.compose(r, 'ピ'))
+			case 'ﾌ':
+				dst = append(dst, s.before.compose(r, 'プ'))
+			case 'ﾍ':
+				dst = append(dst, s.before.compose(r, 'ペ'))
+			case 'ﾎ':
+				dst = append(dst, s.before.compose(r, 'ポ'))
+			default:
+				dst = append(dst, hankakuKatakanaToZenkakuKatakanaSimple(*s.before))
+				dst = append(dst, r.convert('゜'))
 			}
+			s.before = nil
+		}
+	default:
+		if s.before != nil {
+			dst = append(dst, hankakuKatakanaToZenkakuKatakanaSimple(*s.before))
+			s.before = nil
 		}
-	}()
-	return out
+		dst = append(dst, hankakuKatakanaToZenkakuKatakanaSimple(r))
+	}
+	return dst
+}
+
+func (s *hankakuKatakanaToZenkakuKatakanaStage) Flush(dst []KanaConverterRune) []KanaConverterRune {
+	if s.before != nil {
+		dst = append(dst, hankakuKatakanaToZenkakuKatakanaSimple(*s.before))
+		s.before = nil
+	}
+	return dst
 }
 
+// ZenkakuHiraganaToHankakuKatakana converts zenkaku hiragana to hankaku
+// katakana. Hiragana without a hankaku form become the nearest one, such as ゐ
+// to ｲ, and combining voiced marks become ﾞ and ﾟ. ゔ, ゕ and ゖ are passed
+// through as mb_convert_kana does, and so are ゝ, ゞ and ゟ, which have no
+// hankaku form.
 func ZenkakuHiraganaToHankakuKatakana(in <-chan KanaConverterRune) <-chan KanaConverterRune {
-	out := make(chan KanaConverterRune)
-	go func() {
-		defer close(out)
-		for r := range in {
-			if r.IsConverted {
-				out <- r
-				continue
-			}
-			switch r.Rune {
-			case '、':
-				out <- KanaConverterRune{Rune: '､', IsConverted: true}
-			case '。':
-				out <- KanaConverterRune{Rune: '｡', IsConverted: true}
-			case '「':
-				out <- KanaConverterRune{Rune: '｢', IsConverted: true}
-			case '」':
-				out <- KanaConverterRune{Rune: '｣', IsConverted: true}
-			case '゛':
-				out <- KanaConverterRune{Rune: 'ﾞ', IsConverted: true}
-			case '゜':
-				out <- KanaConverterRune{Rune: 'ﾟ', IsConverted: true}
-			case 'ぁ':
-				out <- KanaConverterRune{Rune: 'ｧ', IsConverted: true}
-			case 'あ':
-				out <- KanaConverterRune{Rune: 'ｱ', IsConverted: true}
-			case 'ぃ':
-				out <- KanaConverterRune{Rune: 'ｨ', IsConverted: true}
-			case 'い':
-				out <- KanaConverterRune{Rune: 'ｲ', IsConverted: true}
-			case 'ぅ':
-				out <- KanaConverterRune{Rune: 'ｩ', IsConverted: true}
-			case 'う':
-				out <- KanaConverterRune{Rune: 'ｳ', IsConverted: true}
-			case 'ぇ':
-				out <- KanaConverterRune{Rune: 'ｪ', IsConverted: true}
-			case 'え':
-				out <- KanaConverterRune{Rune: 'ｴ', IsConverted: true}
-			case 'ぉ':
-				out <- KanaConverterRune{Rune: 'ｫ', IsConverted: true}
-			case 'お':
-				out <- KanaConverterRune{Rune: 'ｵ', IsConverted: true}
-			case 'か':
-				out <- KanaConverterRune{Rune: 'ｶ', IsConverted: true}
-			case 'が':
-				out <- KanaConverterRune{Rune: 'ｶ', IsConverted: true}
-				out <- KanaConverterRune{Rune: 'ﾞ', IsConverted: true}
-			case 'き':
-				out <- KanaConverterRune{Rune: 'ｷ', IsConverted: true}
-			case 'ぎ':
-				out <- KanaConverterRune{Rune: 'ｷ', IsConverted: true}
-				out <- KanaConverterRune{Rune: 'ﾞ', IsConverted: true}
-			case 'く':
-				out <- KanaConverterRune{Rune: 'ｸ', IsConverted: true}
-			case 'ぐ':
-				out <- KanaConverterRune{Rune: 'ｸ', IsConverted: true}
-				out <- KanaConverterRune{Rune: 'ﾞ', IsConverted: true}
-			case 'け':
-				out <- KanaConverterRune{Rune: 'ｹ', IsConverted: true}
-			case 'げ':
-				out <- KanaConverterRune{Rune: 'ｹ', IsConverted: true}
-				out <- KanaConverterRune{Rune: 'ﾞ', IsConverted: true}
-			case 'こ':
-				out <- KanaConverterRune{Rune: 'ｺ', IsConverted: true}
-			case 'ご':
-				out <- KanaConverterRune{Rune: 'ｺ', IsConverted: true}
-				out <- KanaConverterRune{Rune: 'ﾞ', IsConverted: true}
-			case 'さ':
-				out <- KanaConverterRune{Rune: 'ｻ', IsConverted: true}
-			case 'ざ':
-				out <- KanaConverterRune{Rune: 'ｻ', IsConverted: true}
-				out <- KanaConverterRune{Rune: 'ﾞ', IsConverted: true}
-			case 'し':
-				out <- KanaConverterRune{Rune: 'ｼ', IsConverted: true}
-			case 'じ':
-				out <- KanaConverterRune{Rune: 'ｼ', IsConverted: true}
-				out <- KanaConverterRune{Rune: 'ﾞ', IsConverted: true}
-			case 'す':
-				out <- KanaConverterRune{Rune: 'ｽ', IsConverted: true}
-			case 'ず':
-				out <- KanaConverterRune{Rune: 'ｽ', IsConverted: true}
-				out <- KanaConverterRune{Rune: 'ﾞ', IsConverted: true}
-			case 'せ':
-				out <- KanaConverterRune{Rune: 'ｾ', IsConverted: true}
-			case 'ぜ':
-				out <- KanaConverterRune{Rune: 'ｾ', IsConverted: true}
-				out <- KanaConverterRune{Rune: 'ﾞ', IsConverted: true}
-			case 'そ':
-				out <- KanaConverterRune{Rune: 'ｿ', IsConverted: true}
-			case 'ぞ':
-				out <- KanaConverterRune{Rune: 'ｿ', IsConverted: true}
-				out <- KanaConverterRune{Rune: 'ﾞ', IsConverted: true}
-			case 'た':
-				out <- KanaConverterRune{Rune: 'ﾀ', IsConverted: true}
-			case 'だ':
-				out <- KanaConverterRune{Rune: 'ﾀ', IsConverted: true}
-				out <- KanaConverterRune{Rune: 'ﾞ', IsConverted: true}
-			case 'ち':
-				out <- KanaConverterRune{Rune: 'ﾁ', IsConverted: true}
-			case 'ぢ':
-				out <- KanaConverterRune{Rune: 'ﾁ', IsConverted: true}
-				out <- KanaConverterRune{Rune: 'ﾞ', IsConverted: true}
-			case 'っ':
-				out <- KanaConverterRune{Rune: 'ｯ', IsConverted: true}
-			case 'つ':
-				out <- KanaConverterRune{Rune: 'ﾂ', IsConverted: true}
-			case 'づ':
-				out <- KanaConverterRune{Rune: 'ﾂ', IsConverted: true}
-				out <- KanaConverterRune{Rune: 'ﾞ', IsConverted: true}
-			case 'て':
-				out <- KanaConverterRune{Rune: 'ﾃ', IsConverted: true}
-			case 'で':
-				out <- KanaConverterRune{Rune: 'ﾃ', IsConverted: true}
-				out <- KanaConverterRune{Rune: 'ﾞ', IsConverted: true}
-			case 'と':
-				out <- KanaConverterRune{Rune: 'ﾄ', IsConverted: true}
-			case 'ど':
-				out <- KanaConverterRune{Rune: 'ﾄ', IsConverted: true}
-				out <- KanaConverterRune{Rune: 'ﾞ', IsConverted: true}
-			case 'な':
-				out <- KanaConverterRune{Rune: 'ﾅ', IsConverted: true}
-			case 'に':
-				out <- KanaConverterRune{Rune: 'ﾆ', IsConverted: true}
-			case 'ぬ':
-				out <- KanaConverterRune{Rune: 'ﾇ', IsConverted: true}
-			case 'ね':
-				out <- KanaConverterRune{Rune: 'ﾈ', IsConverted: true}
-			case 'の':
-				out <- KanaConverterRune{Rune: 'ﾉ', IsConverted: true}
-			case 'は':
-				out <- KanaConverterRune{Rune: 'ﾊ', IsConverted: true}
-			case 'ば':
-				out <- KanaConverterRune{Rune: 'ﾊ', IsConverted: true}
-				out <- KanaConverterRune{Rune: 'ﾞ', IsConverted: true}
-			case 'ぱ':
-				out <- KanaConverterRune{Rune: 'ﾊ', IsConverted: true}
-				out <- KanaConverterRune{Rune: 'ﾟ', IsConverted: true}
-			case 'ひ':
-				out <- KanaConverterRune{Rune: 'ﾋ', IsConverted: true}
-			case 'び':
-				out <- KanaConverterRune{Rune: 'ﾋ', IsConverted: true}
-				out <- KanaConverterRune{Rune: 'ﾞ', IsConverted: true}
-			case 'ぴ':
-				out <- KanaConverterRune{Rune: 'ﾋ', IsConverted: true}
-				out <- KanaConverterRune{Rune: 'ﾟ', IsConverted: true}
-			case 'ふ':
-				out <- KanaConverterRune{Rune: 'ﾌ', IsConverted: true}
-			case 'ぶ':
-				out <- KanaConverterRune{Rune: 'ﾌ', IsConverted: true}
-				out <- KanaConverterRune{Rune: 'ﾞ', IsConverted: true}
-			case 'ぷ':
-				out <- KanaConverterRune{Rune: 'ﾌ', IsConverted: true}
-				out <- KanaConverterRune{Rune: 'ﾟ', IsConverted: true}
-			case 'へ':
-				out <- KanaConverterRune{Rune: 'ﾍ', IsConverted: true}
-			case 'べ':
-				out <- KanaConverterRune{Rune: 'ﾍ', IsConverted: true}
-				out <- KanaConverterRune{Rune: 'ﾞ', IsConverted: true}
-			case 'ぺ':
-				out <- KanaConverterRune{Rune: 'ﾍ', IsConverted: true}
-				out <- KanaConverterRune{Rune: 'ﾟ', IsConverted: true}
-			case 'ほ':
-				out <- KanaConverterRune{Rune: 'ﾎ', IsConverted: true}
-			case 'ぼ':
-				out <- KanaConverterRune{Rune: 'ﾎ', IsConverted: true}
-				out <- KanaConverterRune{Rune: 'ﾞ', IsConverted: true}
-			case 'ぽ':
-				out <- KanaConverterRune{Rune: 'ﾎ', IsConverted: true}
-				out <- KanaConverterRune{Rune: 'ﾟ', IsConverted: true}
-			case 'ま':
-				out <- KanaConverterRune{Rune: 'ﾏ', IsConverted: true}
-			case 'み':
-				out <- KanaConverterRune{Rune: 'ﾐ', IsConverted: true}
-			case 'む':
-				out <- KanaConverterRune{Rune: 'ﾑ', IsConverted: true}
-			case 'め':
-				out <- KanaConverterRune{Rune: 'ﾒ', IsConverted: true}
-			case 'も':
-				out <- KanaConverterRune{Rune: 'ﾓ', IsConverted: true}
-			case 'ゃ':
-				out <- KanaConverterRune{Rune: 'ｬ', IsConverted: true}
-			case 'や':
-				out <- KanaConverterRune{Rune: 'ﾔ', IsConverted: true}
-			case 'ゅ':
-				out <- KanaConverterRune{Rune: 'ｭ', IsConverted: true}
-			case 'ゆ':
-				out <- KanaConverterRune{Rune: 'ﾕ', IsConverted: true}
-			case 'ょ':
-				out <- KanaConverterRune{Rune: 'ｮ', IsConverted: true}
-			case 'よ':
-				out <- KanaConverterRune{Rune: 'ﾖ', IsConverted: true}
-			case 'ら':
-				out <- KanaConverterRune{Rune: 'ﾗ', IsConverted: true}
-			case 'り':
-				out <- KanaConverterRune{Rune: 'ﾘ', IsConverted: true}
-			case 'る':
-				out <- KanaConverterRune{Rune: 'ﾙ', IsConverted: true}
-			case 'れ':
-				out <- KanaConverterRune{Rune: 'ﾚ', IsConverted: true}
-			case 'ろ':
-				out <- KanaConverterRune{Rune: 'ﾛ', IsConverted: true}
-			case 'ゎ':
-				out <- KanaConverterRune{Rune: 'ﾜ', IsConverted: true}
-			case 'わ':
-				out <- KanaConverterRune{Rune: 'ﾜ', IsConverted: true}
-			case 'ゐ':
-				out <- KanaConverterRune{Rune: 'ｲ', IsConverted: true}
-			case 'ゑ':
-				out <- KanaConverterRune{Rune: 'ｴ', IsConverted: true}
-			case 'を':
-				out <- KanaConverterRune{Rune: 'ｦ', IsConverted: true}
-			case 'ん':
-				out <- KanaConverterRune{Rune: 'ﾝ', IsConverted: true}
-			case '・':
-				out <- KanaConverterRune{Rune: '･', IsConverted: true}
-			case 'ー':
-				out <- KanaConverterRune{Rune: 'ｰ', IsConverted: true}
-			default:
-				out <- r
-			}
-		}
-	}()
-	return out
+	return convertForKanaConverter(KanaConverterFunc(zenkakuHiraganaToHankakuKatakana), in)
 }
 
-func hankakuKatakanaToZenkakuHiraganaSimple(in rune) KanaConverterRune {
-	switch in {
+func zenkakuHiraganaToHankakuKatakana(dst []KanaConverterRune, r KanaConverterRune) []KanaConverterRune {
+	if r.IsConverted {
+		return append(dst, r)
+	}
+	switch r.Rune {
+	case '、':
+		dst = append(dst, r.convert('､'))
+	case '。':
+		dst = append(dst, r.convert('｡'))
+	case '「':
+		dst = append(dst, r.convert('｢'))
+	case '」':
+		dst = append(dst, r.convert('｣'))
+	case '゛', '\u3099':
+		dst = append(dst, r.convert('ﾞ'))
+	case '゜', '\u309a':
+		dst = append(dst, r.convert('ﾟ'))
+	case 'ぁ':
+		dst = append(dst, r.convert('ｧ'))
+	case 'あ':
+		dst = append(dst, r.convert('ｱ'))
+	case 'ぃ':
+		dst = append(dst, r.convert('ｨ'))
+	case 'い':
+		dst = append(dst, r.convert('ｲ'))
+	case 'ぅ':
+		dst = append(dst, r.convert('ｩ'))
+	case 'う':
+		dst = append(dst, r.convert('ｳ'))
+	case 'ぇ':
+		dst = append(dst, r.convert('ｪ'))
+	case 'え':
+		dst = append(dst, r.convert('ｴ'))
+	case 'ぉ':
+		dst = append(dst, r.convert('ｫ'))
+	case 'お':
+		dst = append(dst, r.convert('ｵ'))
+	case 'か':
+		dst = append(dst, r.convert('ｶ'))
+	case 'が':
+		dst = append(dst, r.convert('ｶ'))
+		dst = append(dst, r.convert('ﾞ'))
+	case 'き':
+		dst = append(dst, r.convert('ｷ'))
+	case 'ぎ':
+		dst = append(dst, r.convert('ｷ'))
+		dst = append(dst, r.convert('ﾞ'))
+	case 'く':
+		dst = append(dst, r.convert('ｸ'))
+	case 'ぐ':
+		dst = append(dst, r.convert('ｸ'))
+		dst = append(dst, r.convert('ﾞ'))
+	case 'け':
+		dst = append(dst, r.convert('ｹ'))
+	case 'げ':
+		dst = append(dst, r.convert('ｹ'))
+		dst = append(dst, r.convert('ﾞ'))
+	case 'こ':
+		dst = append(dst, r.convert('ｺ'))
+	case 'ご':
+		dst = append(dst, r.convert('ｺ'))
+		dst = append(dst, r.convert('ﾞ'))
+	case 'さ':
+		dst = append(dst, r.convert('ｻ'))
+	case 'ざ':
+		dst = append(dst, r.convert('ｻ'))
+		dst = append(dst, r.convert('ﾞ'))
+	case 'し':
+		dst = append(dst, r.convert('ｼ'))
+	case 'じ':
+		dst = append(dst, r.convert('ｼ'))
+		dst = append(dst, r.convert('ﾞ'))
+	case 'す':
+		dst = append(dst, r.convert('ｽ'))
+	case 'ず':
+		dst = append(dst, r.convert('ｽ'))
+		dst = append(dst, r.convert('ﾞ'))
+	case 'せ':
+		dst = append(dst, r.convert('ｾ'))
+	case 'ぜ':
+		dst = append(dst, r.convert('ｾ'))
+		dst = append(dst, r.convert('ﾞ'))
+	case 'そ':
+		dst = append(dst, r.convert('ｿ'))
+	case 'ぞ':
+		dst = append(dst, r.convert('ｿ'))
+		dst = append(dst, r.convert('ﾞ'))
+	case 'た':
+		dst = append(dst, r.convert('ﾀ'))
+	case 'だ':
+		dst = append(dst, r.convert('ﾀ'))
+		dst = append(dst, r.convert('ﾞ'))
+	case 'ち':
+		dst = append(dst, r.convert('ﾁ'))
+	case 'ぢ':
+		dst = append(dst, r.convert('ﾁ'))
+		dst = append(dst, r.convert('ﾞ'))
+	case 'っ':
+		dst = append(dst, r.convert('ｯ'))
+	case 'つ':
+		dst = append(dst, r.convert('ﾂ'))
+	case 'づ':
+		dst = append(dst, r.convert('ﾂ'))
+		dst = append(dst, r.convert('ﾞ'))
+	case 'て':
+		dst = append(dst, r.convert('ﾃ'))
+	case 'で':
+		dst = append(dst, r.convert('ﾃ'))
+		dst = append(dst, r.convert('ﾞ'))
+	case 'と':
+		dst = append(dst, r.convert('ﾄ'))
+	case 'ど':
+		dst = append(dst, r.convert('ﾄ'))
+		dst = append(dst, r.convert('ﾞ'))
+	case 'な':
+		dst = append(dst, r.convert('ﾅ'))
+	case 'に':
+		dst = append(dst, r.convert('ﾆ'))
+	case 'ぬ':
+		dst = append(dst, r.convert('ﾇ'))
+	case 'ね':
+		dst = append(dst, r.convert('ﾈ'))
+	case 'の':
+		dst = append(dst, r.convert('ﾉ'))
+	case 'は':
+		dst = append(dst, r.convert('ﾊ'))
+	case 'ば':
+		dst = append(dst, r.convert('ﾊ'))
+		dst = append(dst, r.convert('ﾞ'))
+	case 'ぱ':
+		dst = append(dst, r.convert('ﾊ'))
+		dst = append(dst, r.convert('ﾟ'))
+	case 'ひ':
+		dst = append(dst, r.convert('ﾋ'))
+	case 'び':
+		dst = append(dst, r.convert('ﾋ'))
+		dst = append(dst, r.convert('ﾞ'))
+	case 'ぴ':
+		dst = append(dst, r.convert('ﾋ'))
+		dst = append(dst, r.convert('ﾟ'))
+	case 'ふ':
+		dst = append(dst, r.convert('ﾌ'))
+	case 'ぶ':
+		dst = append(dst, r.convert('ﾌ'))
+		dst = append(dst, r.convert('ﾞ'))
+	case 'ぷ':
+		dst = append(dst, r.convert('ﾌ'))
+		dst = append(dst, r.convert('ﾟ'))
+	case 'へ':
+		dst = append(dst, r.convert('ﾍ'))
+	case 'べ':
+		dst = append(dst, r.convert('ﾍ'))
+		dst = append(dst, r.convert('ﾞ'))
+	case 'ぺ':
+		dst = append(dst, r.convert('ﾍ'))
+		dst = append(dst, r.convert('ﾟ'))
+	case 'ほ':
+		dst = append(dst, r.convert('ﾎ'))
+	case 'ぼ':
+		dst = append(dst, r.convert('ﾎ'))
+		dst = append(dst, r.convert('ﾞ'))
+	case 'ぽ':
+		dst = append(dst, r.convert('ﾎ'))
+		dst = append(dst, r.convert('ﾟ'))
+	case 'ま':
+		dst = append(dst, r.convert('ﾏ'))
+	case 'み':
+		dst = append(dst, r.convert('ﾐ'))
+	case 'む':
+		dst = append(dst, r.convert('ﾑ'))
+	case 'め':
+		dst = append(dst, r.convert('ﾒ'))
+	case 'も':
+		dst = append(dst, r.convert('ﾓ'))
+	case 'ゃ':
+		dst = append(dst, r.convert('ｬ'))
+	case 'や':
+		dst = append(dst, r.convert('ﾔ'))
+	case 'ゅ':
+		dst = append(dst, r.convert('ｭ'))
+	case 'ゆ':
+		dst = append(dst, r.convert('ﾕ'))
+	case 'ょ':
+		dst = append(dst, r.convert('ｮ'))
+	case 'よ':
+		dst = append(dst, r.convert('ﾖ'))
+	case 'ら':
+		dst = append(dst, r.convert('ﾗ'))
+	case 'り':
+		dst = append(dst, r.convert('ﾘ'))
+	case 'る':
+		dst = append(dst, r.convert('ﾙ'))
+	case 'れ':
+		dst = append(dst, r.convert('ﾚ'))
+	case 'ろ':
+		dst = append(dst, r.convert('ﾛ'))
+	case 'ゎ':
+		dst = append(dst, r.convert('ﾜ'))
+	case 'わ':
+		dst = append(dst, r.convert('ﾜ'))
+	case 'ゐ':
+		dst = append(dst, r.convert('ｲ'))
+	case 'ゑ':
+		dst = append(dst, r.convert('ｴ'))
+	case 'を':
+		dst = append(dst, r.convert('ｦ'))
+	case 'ん':
+		dst = append(dst, r.convert('ﾝ'))
+	case '・':
+		dst = append(dst, r.convert('･'))
+	case 'ー':
+		dst = append(dst, r.convert('ｰ'))
+	default:
+		dst = append(dst, r)
+	}
+	return dst
+}
+
+func hankakuKatakanaToZenkakuHiraganaSimple(r KanaConverterRune) KanaConverterRune {
+	switch r.Rune {
 	case '｡':
-		return KanaConverterRune{Rune: '。', IsConverted: true}
+		return r.convert('。')
 	case '｢':
-		return KanaConverterRune{Rune: '「', IsConverted: true}
+		return r.convert('「')
 	case '｣':
-		return KanaConverterRune{Rune: '」', IsConverted: true}
+		return r.convert('」')
 	case '､':
-		return KanaConverterRune{Rune: '、', IsConverted: true}
+		return r.convert('、')
 	case '･':
-		return KanaConverterRune{Rune: '・', IsConverted: true}
+		return r.convert('・')
 	case 'ｦ':
-		return KanaConverterRune{Rune: 'を', IsConverted: true}
+		return r.convert('を')
 	case 'ｧ':
-		return KanaConverterRune{Rune: 'ぁ', IsConverted: true}
+		return r.convert('ぁ')
 	case 'ｨ':
-		return KanaConverterRune{Rune: 'ぃ', IsConverted: true}
+		return r.convert('ぃ')
 	case 'ｩ':
-		return KanaConverterRune{Rune: 'ぅ', IsConverted: true}
+		return r.convert('ぅ')
 	case 'ｪ':
-		return KanaConverterRune{Rune: 'ぇ', IsConverted: true}
+		return r.convert('ぇ')
 	case 'ｫ':
-		return KanaConverterRune{Rune: 'ぉ', IsConverted: true}
+		return r.convert('ぉ')
 	case 'ｬ':
-		return KanaConverterRune{Rune: 'ゃ', IsConverted: true}
+		return r.convert('ゃ')
 	case 'ｭ':
-		return KanaConverterRune{Rune: 'ゅ', IsConverted: true}
+		return r.convert('ゅ')
 	case 'ｮ':
-		return KanaConverterRune{Rune: 'ょ', IsConverted: true}
+		return r.convert('ょ')
 	case 'ｯ':
-		return KanaConverterRune{Rune: 'っ', IsConverted: true}
+		return r.convert('っ')
 	case 'ｰ':
-		return KanaConverterRune{Rune: 'ー', IsConverted: true}
+		return r.convert('ー')
 	case 'ｱ':
-		return KanaConverterRune{Rune: 'あ', IsConverted: true}
+		return r.convert('あ')
 	case 'ｲ':
-		return KanaConverterRune{Rune: 'い', IsConverted: true}
+		return r.convert('い')
 	case 'ｳ':
-		return KanaConverterRune{Rune: 'う', IsConverted: true}
+		return r.convert('う')
 	case 'ｴ':
-		return KanaConverterRune{Rune: 'え', IsConverted: true}
+		return r.convert('え')
 	case 'ｵ':
-		return KanaConverterRune{Rune: 'お', IsConverted: true}
+		return r.convert('お')
 	case 'ｶ':
-		return KanaConverterRune{Rune: 'か', IsConverted: true}
+		return r.convert('か')
 	case 'ｷ':
-		return KanaConverterRune{Rune: 'き', IsConverted: true}
+		return r.convert('き')
 	case 'ｸ':
-		return KanaConverterRune{Rune: 'く', IsConverted: true}
+		return r.convert('く')
 	case 'ｹ':
-		return KanaConverterRune{Rune: 'け', IsConverted: true}
+		return r.convert('け')
 	case 'ｺ':
-		return KanaConverterRune{Rune: 'こ', IsConverted: true}
+		return r.convert('こ')
 	case 'ｻ':
-		return KanaConverterRune{Rune: 'さ', IsConverted: true}
+		return r.convert('さ')
 	case 'ｼ':
-		return KanaConverterRune{Rune: 'し', IsConverted: true}
+		return r.convert('し')
 	case 'ｽ':
-		return KanaConverterRune{Rune: 'す', IsConverted: true}
+		return r.convert('す')
 	case 'ｾ':
-		return KanaConverterRune{Rune: 'せ', IsConverted: true}
+		return r.convert('せ')
 	case 'ｿ':
-		return KanaConverterRune{Rune: 'そ', IsConverted: true}
+		return r.convert('そ')
 	case 'ﾀ':
-		return KanaConverterRune{Rune: 'た', IsConverted: true}
+		return r.convert('た')
 	case 'ﾁ':
-		return KanaConverterRune{Rune: 'ち', IsConverted: true}
+		return r.convert('ち')
 	case 'ﾂ':
-		return KanaConverterRune{Rune: 'つ', IsConverted: true}
+		return r.convert('つ')
 	case 'ﾃ':
-		return KanaConverterRune{Rune: 'て', IsConverted: true}
+		return r.convert('て')
 	case 'ﾄ':
-		return KanaConverterRune{Rune: 'と', IsConverted: true}
+		return r.convert('と')
 	case 'ﾅ':
-		return KanaConverterRune{Rune: 'な', IsConverted: true}
+		return r.convert('な')
 	case 'ﾆ':
-		return KanaConverterRune{Rune: 'に', IsConverted: true}
+		return r.convert('に')
 	case 'ﾇ':
-		return KanaConverterRune{Rune: 'ぬ', IsConverted: true}
+		return r.convert('ぬ')
 	case 'ﾈ':
-		return KanaConverterRune{Rune: 'ね', IsConverted: true}
+		return r.convert('ね')
 	case 'ﾉ':
-		return KanaConverterRune{Rune: 'の', IsConverted: true}
+		return r.convert('の')
 	case 'ﾊ':
-		return KanaConverterRune{Rune: 'は', IsConverted: true}
+		return r.convert('は')
 	case 'ﾋ':
-		return KanaConverterRune{Rune: 'ひ', IsConverted: true}
+		return r.convert('ひ')
 	case 'ﾌ':
-		return KanaConverterRune{Rune: 'ふ', IsConverted: true}
+		return r.convert('ふ')
 	case 'ﾍ':
-		return KanaConverterRune{Rune: 'へ', IsConverted: true}
+		return r.convert('へ')
 	case 'ﾎ':
-		return KanaConverterRune{Rune: 'ほ', IsConverted: true}
+		return r.convert('ほ')
 	case 'ﾏ':
-		return KanaConverterRune{Rune: 'ま', IsConverted: true}
+		return r.convert('ま')
 	case 'ﾐ':
-		return KanaConverterRune{Rune: 'み', IsConverted: true}
+		return r.convert('み')
 	case 'ﾑ':
-		return KanaConverterRune{Rune: 'む', IsConverted: true}
+		return r.convert('む')
 	case 'ﾒ':
-		return KanaConverterRune{Rune: 'め', IsConverted: true}
+		return r.convert('め')
 	case 'ﾓ':
-		return KanaConverterRune{Rune: 'も', IsConverted: true}
+		return r.convert('も')
 	case 'ﾔ':
-		return KanaConverterRune{Rune: 'や', IsConverted: true}
+		return r.convert('や')
 	case 'ﾕ':
-		return KanaConverterRune{Rune: 'ゆ', IsConverted: true}
+		return r.convert('ゆ')
 	case 'ﾖ':
-		return KanaConverterRune{Rune: 'よ', IsConverted: true}
+		return r.convert('よ')
 	case 'ﾗ':
-		return KanaConverterRune{Rune: 'ら', IsConverted: true}
+		return r.convert('ら')
 	case 'ﾘ':
-		return KanaConverterRune{Rune: 'り', IsConverted: true}
+		return r.convert('り')
 	case 'ﾙ':
-		return KanaConverterRune{Rune: 'る', IsConverted: true}
+		return r.convert('る')
 	case 'ﾚ':
-		return KanaConverterRune{Rune: 'れ', IsConverted: true}
+		return r.convert('れ')
 	case 'ﾛ':
-		return KanaConverterRune{Rune: 'ろ', IsConverted: true}
+		return r.convert('ろ')
 	case 'ﾜ':
-		return KanaConverterRune{Rune: 'わ', IsConverted: true}
+		return r.convert('わ')
 	case 'ﾝ':
-		return KanaConverterRune{Rune: 'ん', IsConverted: true}
+		return r.convert('ん')
 	case 'ﾞ':
-		return KanaConverterRune{Rune: '゛', IsConverted: true}
+		return r.convert('゛')
 	case 'ﾟ':
-		return KanaConverterRune{Rune: '゜', IsConverted: true}
+		return r.convert('゜')
 	default:
-		return KanaConverterRune{Rune: in}
+		return r
 	}
 }
 
+// HankakuKatakanaToZenkakuHiragana converts hankaku katakana to zenkaku
+// hiragana. With v, a base followed by ﾞ or ﾟ is composed, such as ｳﾞ to ゔ.
+// ﾜﾞ and ｦﾞ have no composed hiragana and become わ゛ and を゛.
 func HankakuKatakanaToZenkakuHiragana(in <-chan KanaConverterRune, v bool) <-chan KanaConverterRune {
-	out := make(chan KanaConverterRune)
-	go func() {
-		defer close(out)
-		if v {
-			var before *rune
-			for r := range in {
-				if r.IsConverted {
-					out <- r
-					continue
-				}
-				switch r.Rune {
-				case 'ｶ', 'ｷ', 'ｸ', 'ｹ', 'ｺ', 'ｻ', 'ｼ', 'ｽ', 'ｾ', 'ｿ', 'ﾀ', 'ﾁ', 'ﾂ', 'ﾃ', 'ﾄ', 'ﾊ', 'ﾋ', 'ﾌ', 'ﾍ', 'ﾎ':
-					if before != nil {
-						out <- hankakuKatakanaToZenkakuHiraganaSimple(*before)
-					}
-					r := r
-					before = &r.Rune
-				case 'ﾞ':
-					if before == nil {
-						out <- KanaConverterRune{Rune: '゛', IsConverted: true}
-					} else {
-						switch *before {
-						case 'ｶ':
-							out <- KanaConverterRune{Rune: 'が', IsConverted: true}
-						case 'ｷ':
-							out <- KanaConverterRune{Rune: 'ぎ', IsConverted: true}
-						case 'ｸ':
-							out <- KanaConverterRune{Rune: 'ぐ', IsConverted: true}
-						case 'ｹ':
-							out <- KanaConverterRune{Rune: 'げ', IsConverted: true}
-						case 'ｺ':
-							out <- KanaConverterRune{Rune: 'ご', IsConverted: true}
-						case 'ｻ':
-							out <- KanaConverterRune{Rune: 'ざ', IsConverted: true}
-						case 'ｼ':
-							out <- KanaConverterRune{Rune: 'じ', IsConverted: true}
-						case 'ｽ':
-							out <- KanaConverterRune{Rune: 'ず', IsConverted: true}
-						case 'ｾ':
-							out <- KanaConverterRune{Rune: 'ぜ', IsConverted: true}
-						case 'ｿ':
-							out <- KanaConverterRune{Rune: 'ぞ', IsConverted: true}
-						case 'ﾀ':
-							out <- KanaConverterRune{Rune: 'だ', IsConverted: true}
-						case 'ﾁ':
-							out <- KanaConverterRune{Rune: 'ぢ', IsConverted: true}
-						case 'ﾂ':
-							out <- KanaConverterRune{Rune: 'づ', IsConverted: true}
-						case 'ﾃ':
-							out <- KanaConverterRune{Rune: 'で', IsConverted: true}
-						case 'ﾄ':
-							out <- KanaConverterRune{Rune: 'ど', IsConverted: true}
-						case 'ﾊ':
-							out <- KanaConverterRune{Rune: 'ば', IsConverted: true}
-						case 'ﾋ':
-							out <- KanaConverterRune{Rune: 'び', IsConverted: true}
-						case 'ﾌ':
-							out <- KanaConverterRune{Rune: 'ぶ', IsConverted: true}
-						case 'ﾍ':
-							out <- KanaConverterRune{Rune: 'べ', IsConverted: true}
-						case 'ﾎ':
-							out <- KanaConverterRune{Rune: 'ぼ', IsConverted: true}
-						default:
-							out <- hankakuKatakanaToZenkakuHiraganaSimple(*before)
-							out <- KanaConverterRune{Rune: '゛', IsConverted: true}
-						}
-						before = nil
-					}
-				case 'ﾟ':
-					if before == nil {
-						out <- KanaConverterRune{Rune: '゜', IsConverted: true}
-					} else {
-						switch *before {
-						case 'ﾊ':
-							out <- KanaConverterRune{Rune: 'ぱ', IsConverted: true}
-						case 'ﾋ':
-							out <- KanaConverterRune{Rune: 'ぴ', IsConverted: true}
-						case 'ﾌ':
-							out <- KanaConverterRune{Rune: 'ぷ', IsConverted: true}
-						case 'ﾍ':
-							out <- KanaConverterRune{Rune: 'ぺ', IsConverted: true}
-						case 'ﾎ':
-							out <- KanaConverterRune{Rune: 'ぽ', IsConverted: true}
-						default:
-							out <- hankakuKatakanaToZenkakuHiraganaSimple(*before)
-							out <- KanaConverterRune{Rune: '゜', IsConverted: true}
-						}
-						before = nil
-					}
-				default:
-					if before != nil {
-						out <- hankakuKatakanaToZenkakuHiraganaSimple(*before)
-						before = nil
-					}
-					out <- hankakuKatakanaToZenkakuHiraganaSimple(r.Rune)
-				}
-			}
-			if before != nil {
-				out <- hankakuKatakanaToZenkakuHiraganaSimple(*before)
+	return convertForKanaConverter(&hankakuKatakanaToZenkakuHiraganaStage{v: v}, in)
+}
+
+type hankakuKatakanaToZenkakuHiraganaStage struct {
+	v      bool
+	before *KanaConverterRune
+}
+
+func (s *hankakuKatakanaToZenkakuHiraganaStage) Push(dst []KanaConverterRune, r KanaConverterRune) []KanaConverterRune {
+	if r.IsConverted {
+		// a base held is not composed with a converted rune, and comes before it
+		return append(s.Flush(dst), r)
+	}
+	if !s.v {
+		return append(dst, hankakuKatakanaToZenkakuHiraganaSimple(r))
+	}
+	switch r.Rune {
+	case 'ｳ', 'ｶ', 'ｷ', 'ｸ', 'ｹ', 'ｺ', 'ｻ', 'ｼ', 'ｽ', 'ｾ', 'ｿ', 'ﾀ', 'ﾁ', 'ﾂ', 'ﾃ', 'ﾄ', 'ﾊ', 'ﾋ', 'ﾌ', 'ﾍ', 'ﾎ':
+		if s.before != nil {
+			dst = append(dst, hankakuKatakanaToZenkakuHiraganaSimple(*s.before))
+		}
+		r := r
+		s.before = &r
+	case 'ﾞ':
+		if s.before == nil {
+			dst = append(dst, r.convert('゛'))
+		} else {
+			switch s.before.Rune {
+			case 'ｳ':
+				dst = append(dst, s.before.compose(r, 'ゔ'))
+			case 'ｶ':
+				dst = append(dst, s.before.compose(r, 'が'))
+			case 'ｷ':
+				dst = append(dst, s.before.compose(r, 'ぎ'))
+			case 'ｸ':
+				dst = append(dst, s.before.compose(r, 'ぐ'))
+			case 'ｹ':
+				dst = append(dst, s.before.compose(r, 'げ'))
+			case 'ｺ':
+				dst = append(dst, s.before.compose(r, 'ご'))
+			case 'ｻ':
+				dst = append(dst, s.before.compose(r, 'ざ'))
+			case 'ｼ':
+				dst = append(dst, s.before.compose(r, 'じ'))
+			case 'ｽ':
+				dst = append(dst, s.before.compose(r, 'ず'))
+			case 'ｾ':
+				dst = append(dst, s.before.compose(r, 'ぜ'))
+			case 'ｿ':
+				dst = append(dst, s.before.compose(r, 'ぞ'))
+			case 'ﾀ':
+				dst = append(dst, s.before.compose(r, 'だ'))
+			case 'ﾁ':
+				dst = append(dst, s.before.compose(r, 'ぢ'))
+			case 'ﾂ':
+				dst = append(dst, s.before.compose(r, 'づ'))
+			case 'ﾃ':
+				dst = append(dst, s.before.compose(r, 'で'))
+			case 'ﾄ':
+				dst = append(dst, s.before.compose(r, 'ど'))
+			case 'ﾊ':
+				dst = append(dst, s.before.compose(r, 'ば'))
+			case 'ﾋ':
+				dst = append(dst, s.before.compose(r, 'び'))
+			case 'ﾌ':
+				dst = append(dst, s.before.compose(r, 'ぶ'))
+			case 'ﾍ':
+				dst = append(dst, s.before.compose(r, 'べ'))
+			case 'ﾎ':
+				dst = append(dst, s.before.compose(r, 'ぼ'))
+			default:
+				dst = append(dst, hankakuKatakanaToZenkakuHiraganaSimple(*s.before))
+				dst = append(dst, r.convert('゛'))
 			}
+			s.before = nil
+		}
+	case 'ﾟ':
+		if s.before == nil {
+			dst = append(dst, r.convert('゜'))
 		} else {
-			for r := range in {
-				if r.IsConverted {
-					out <- r
-					continue
-				}
-				out <- hankakuKatakanaToZenkakuHiraganaSimple(r.Rune)
+			switch s.before.Rune {
+			case 'ﾊ':
+				dst = append(dst, s.before.compose(r, 'ぱ'))
+			case 'ﾋ':
+				dst = append(dst, s.before.compose(r, 'ぴ'))
+			case 'ﾌ':
+				dst = append(dst, s.before.compose(r, 'ぷ'))
+			case 'ﾍ':
+				dst = append(dst, s.before.compose(r, 'ぺ'))
+			case 'ﾎ':
+				dst = append(dst, s.before.compose(r, 'ぽ'))
+			default:
+				dst = append(dst, hankakuKatakanaToZenkakuHiraganaSimple(*s.before))
+				dst = append(dst, r.convert('゜'))
 			}
+			s.before = nil
+		}
+	default:
+		if s.before != nil {
+			dst = append(dst, hankakuKatakanaToZenkakuHiraganaSimple(*s.before))
+			s.before = nil
 		}
-	}()
-	return out
+		dst = append(dst, hankakuKatakanaToZenkakuHiraganaSimple(r))
+	}
+	return dst
+}
+
+func (s *hankakuKatakanaToZenkakuHiraganaStage) Flush(dst []KanaConverterRune) []KanaConverterRune {
+	if s.before != nil {
+		dst = append(dst, hankakuKatakanaToZenkakuHiraganaSimple(*s.before))
+		s.before = nil
+	}
+	return dst
 }
 
+// ZenkakuKatakanaToZenkakuHiragana converts ァ to ン, ヽ and ヾ to hiragana.
+// ヴ, ヵ, ヶ and ヷ to ヺ are passed through as mb_convert_kana does, and so are
+// ゠, ヿ and the small katakana of U+31F0 to U+31FF, which have no hiragana.
 func ZenkakuKatakanaToZenkakuHiragana(in <-chan KanaConverterRune) <-chan KanaConverterRune {
-	out := make(chan KanaConverterRune)
-	go func() {
-		defer close(out)
-		for r := range in {
-			if r.IsConverted {
-				out <- r
-				continue
-			}
-			switch {
-			case r.Rune >= 'ァ' && r.Rune <= 'ン', r.Rune == 'ヽ', r.Rune == 'ヾ':
-				out <- KanaConverterRune{Rune: 'ぁ' + r.Rune - 'ァ', IsConverted: true}
-			default:
-				out <- r
-			}
-		}
-	}()
-	return out
+	return convertForKanaConverter(KanaConverterFunc(zenkakuKatakanaToZenkakuHiragana), in)
+}
+
+func zenkakuKatakanaToZenkakuHiragana(dst []KanaConverterRune, r KanaConverterRune) []KanaConverterRune {
+	if r.IsConverted {
+		return append(dst, r)
+	}
+	switch {
+	case r.Rune >= 'ァ' && r.Rune <= 'ン', r.Rune == 'ヽ', r.Rune == 'ヾ':
+		dst = append(dst, r.convert('ぁ'+r.Rune-'ァ'))
+	default:
+		dst = append(dst, r)
+	}
+	return dst
 }
 
+// ZenkakuHiraganaToZenkakuKatakana converts ぁ to ん, ゝ and ゞ to katakana.
+// ゔ, ゕ and ゖ are passed through as mb_convert_kana does, and ゟ has no
+// katakana.
 func ZenkakuHiraganaToZenkakuKatakana(in <-chan KanaConverterRune) <-chan KanaConverterRune {
-	out := make(chan KanaConverterRune)
-	go func() {
-		defer close(out)
-		for r := range in {
-			if r.IsConverted {
-				out <- r
-				continue
-			}
-			switch {
-			case r.Rune >= 'ぁ' && r.Rune <= 'ん', r.Rune == 'ゝ', r.Rune == 'ゞ':
-				out <- KanaConverterRune{Rune: 'ァ' + r.Rune - 'ぁ', IsConverted: true}
-			default:
-				out <- r
-			}
-		}
-	}()
-	return out
+	return convertForKanaConverter(KanaConverterFunc(zenkakuHiraganaToZenkakuKatakana), in)
+}
+
+func zenkakuHiraganaToZenkakuKatakana(dst []KanaConverterRune, r KanaConverterRune) []KanaConverterRune {
+	if r.IsConverted {
+		return append(dst, r)
+	}
+	switch {
+	case r.Rune >= 'ぁ' && r.Rune <= 'ん', r.Rune == 'ゝ', r.Rune == 'ゞ':
+		dst = append(dst, r.convert('ァ'+r.Rune-'ぁ'))
+	default:
+		dst = append(dst, r)
+	}
+	return dst
 }
 
 func NewKanaConverters(mode string) ([]func(<-chan KanaConverterRune) <-chan KanaConverterRune, error) {
@@ -1164,80 +1205,173 @@ func NewKanaConverters(mode string) ([]func(<-chan KanaConverterRune) <-chan Kan
 		return nil, err
 	}
 	var converters []func(<-chan KanaConverterRune) <-chan KanaConverterRune
+	for _, b := range newKanaConverterStages(options) {
+		b := b
+		converters = append(converters, func(in <-chan KanaConverterRune) <-chan KanaConverterRune {
+			return convertForKanaConverter(b.newStage(), in)
+		})
+	}
+	return converters, nil
+}
+
+// NewKanaConverterStages returns the synchronous stages for mode in the same
+// order as NewKanaConverters. Stages may keep state between Push calls, so the
+// returned slice must not be shared between conversions.
+func NewKanaConverterStages(mode string) ([]KanaConverterStage, error) {
+	options, err := NewKanaConverterOptions(mode)
+	if err != nil {
+		return nil, err
+	}
+	var stages []KanaConverterStage
+	for _, b := range newKanaConverterStages(options) {
+		stages = append(stages, b.newStage())
+	}
+	return stages, nil
+}
+
+// kanaConverterStageBuilder creates a stage for each conversion. name is the
+// name of the stage reported by KanaConverter.Explain, and foldable tells that
+// the stage may be folded into a kanaConverterTable.
+type kanaConverterStageBuilder struct {
+	name     string
+	newStage func() KanaConverterStage
+	foldable bool
+}
+
+func statelessKanaConverterStage(name string, f KanaConverterFunc) kanaConverterStageBuilder {
+	return kanaConverterStageBuilder{
+		name: name,
+		newStage: func() KanaConverterStage {
+			return f
+		},
+		foldable: true,
+	}
+}
+
+// newKanaConverterStages returns the built-in stages followed by the
+// registered stages and mappings of options.
+func newKanaConverterStages(options *KanaConverterOptions) []kanaConverterStageBuilder {
+	converters := newKanaConverterWidthStages(options)
+	// numerals are read after the conversions between hankaku and zenkaku,
+	// whatever width the digits are given in
+	if options.optj {
+		converters = append(converters, kanaConverterStageBuilder{
+			name: "KanjiNumeralToArabicNumeral",
+			newStage: func() KanaConverterStage {
+				return &kanjiNumeralToArabicNumeralStage{}
+			},
+		})
+	}
+	if options.optJ || options.optD {
+		positional := options.optJ
+		converters = append(converters, kanaConverterStageBuilder{
+			name: "ArabicNumeralToKanjiNumeral",
+			newStage: func() KanaConverterStage {
+				return &arabicNumeralToKanjiNumeralStage{positional: positional}
+			},
+		})
+	}
+	// the voiced marks are composed or decomposed after the conversions
+	// between hankaku and zenkaku, which may leave or split them
+	if options.optM {
+		converters = append(converters, kanaConverterStageBuilder{
+			name: "ComposeVoicedMarks",
+			newStage: func() KanaConverterStage {
+				return &composeVoicedMarksStage{}
+			},
+		})
+	}
+	if options.optm {
+		converters = append(converters, statelessKanaConverterStage("DecomposeVoicedMarks", decomposeVoicedMarks))
+	}
+	return append(converters, options.stages...)
+}
+
+func newKanaConverterWidthStages(options *KanaConverterOptions) []kanaConverterStageBuilder {
+	var converters []kanaConverterStageBuilder
 	if options.optr {
-		converters = append(converters, ZenkakuEnglishToHankakuEnglish)
+		converters = append(converters, statelessKanaConverterStage("ZenkakuEnglishToHankakuEnglish", zenkakuEnglishToHankakuEnglish))
 	}
 	if options.optR {
-		converters = append(converters, HankakuEnglishToZenkakuEnglish)
+		converters = append(converters, statelessKanaConverterStage("HankakuEnglishToZenkakuEnglish", hankakuEnglishToZenkakuEnglish))
 	}
 	if options.optn {
-		converters = append(converters, ZenkakuNumberToHankakuNumber)
+		converters = append(converters, statelessKanaConverterStage("ZenkakuNumberToHankakuNumber", zenkakuNumberToHankakuNumber))
 	}
 	if options.optN {
-		converters = append(converters, HankakuNumberToZenkakuNumber)
+		converters = append(converters, statelessKanaConverterStage("HankakuNumberToZenkakuNumber", hankakuNumberToZenkakuNumber))
 	}
 	if options.opta {
-		converters = append(converters, ZenkakuEnglishNumberToHankakuEnglishNumber)
+		converters = append(converters, statelessKanaConverterStage("ZenkakuEnglishNumberToHankakuEnglishNumber", zenkakuEnglishNumberToHankakuEnglishNumber))
 	}
 	if options.optA {
-		converters = append(converters, HankakuEnglishNumberToZenkakuEnglishNumber)
+		converters = append(converters, statelessKanaConverterStage("HankakuEnglishNumberToZenkakuEnglishNumber", hankakuEnglishNumberToZenkakuEnglishNumber))
 	}
 	if options.opts {
-		converters = append(converters, ZenkakuSpaceToHankakuSpace)
+		converters = append(converters, statelessKanaConverterStage("ZenkakuSpaceToHankakuSpace", zenkakuSpaceToHankakuSpace))
 	}
 	if options.optS {
-		converters = append(converters, HankakuSpaceToZenkakuSpace)
+		converters = append(converters, statelessKanaConverterStage("HankakuSpaceToZenkakuSpace", hankakuSpaceToZenkakuSpace))
+	}
+
+	hankakuKatakanaToZenkakuHiragana := kanaConverterStageBuilder{
+		name: "HankakuKatakanaToZenkakuHiragana",
+		newStage: func() KanaConverterStage {
+			return &hankakuKatakanaToZenkakuHiraganaStage{v: options.optV}
+		},
+		foldable: true,
+	}
+	hankakuKatakanaToZenkakuKatakana := kanaConverterStageBuilder{
+		name: "HankakuKatakanaToZenkakuKatakana",
+		newStage: func() KanaConverterStage {
+			return &hankakuKatakanaToZenkakuKatakanaStage{v: options.optV}
+		},
+		foldable: true,
 	}
 
 	// kc, kC, KH, hc and hC are not combined
 	if options.optk {
 		if options.opth {
-			converters = append(converters, ZenkakuHiraganaToHankakuKatakana)
+			converters = append(converters, statelessKanaConverterStage("ZenkakuHiraganaToHankakuKatakana", zenkakuHiraganaToHankakuKatakana))
 		}
 		if options.optH {
-			converters = append(converters, func(in <-chan KanaConverterRune) <-chan KanaConverterRune {
-				return HankakuKatakanaToZenkakuHiragana(in, options.optV)
-			})
+			converters = append(converters, hankakuKatakanaToZenkakuHiragana)
 		}
-		converters = append(converters, ZenkakuKatakanaToHankakuKatakana)
-		return converters, nil
+		converters = append(converters, statelessKanaConverterStage("ZenkakuKatakanaToHankakuKatakana", zenkakuKatakanaToHankakuKatakana))
+		return converters
 	}
 	if options.optK {
 		if options.optc {
-			converters = append(converters, ZenkakuKatakanaToZenkakuHiragana)
+			converters = append(converters, statelessKanaConverterStage("ZenkakuKatakanaToZenkakuHiragana", zenkakuKatakanaToZenkakuHiragana))
 		}
-		converters = append(converters, func(in <-chan KanaConverterRune) <-chan KanaConverterRune {
-			return HankakuKatakanaToZenkakuKatakana(in, options.optV)
-		})
+		converters = append(converters, hankakuKatakanaToZenkakuKatakana)
 		if options.opth {
-			converters = append(converters, ZenkakuHiraganaToHankakuKatakana)
+			converters = append(converters, statelessKanaConverterStage("ZenkakuHiraganaToHankakuKatakana", zenkakuHiraganaToHankakuKatakana))
 		}
 		if options.optC {
-			converters = append(converters, ZenkakuHiraganaToZenkakuKatakana)
+			converters = append(converters, statelessKanaConverterStage("ZenkakuHiraganaToZenkakuKatakana", zenkakuHiraganaToZenkakuKatakana))
 		}
-		return converters, nil
+		return converters
 	}
 	if options.opth {
-		converters = append(converters, ZenkakuHiraganaToHankakuKatakana)
-		return converters, nil
+		converters = append(converters, statelessKanaConverterStage("ZenkakuHiraganaToHankakuKatakana", zenkakuHiraganaToHankakuKatakana))
+		return converters
 	}
 	if options.optH {
 		if options.optC {
-			converters = append(converters, ZenkakuHiraganaToZenkakuKatakana)
+			converters = append(converters, statelessKanaConverterStage("ZenkakuHiraganaToZenkakuKatakana", zenkakuHiraganaToZenkakuKatakana))
 		}
-		converters = append(converters, func(in <-chan KanaConverterRune) <-chan KanaConverterRune {
-			return HankakuKatakanaToZenkakuHiragana(in, options.optV)
-		})
+		converters = append(converters, hankakuKatakanaToZenkakuHiragana)
 		if options.optc {
-			converters = append(converters, ZenkakuKatakanaToZenkakuHiragana)
+			converters = append(converters, statelessKanaConverterStage("ZenkakuKatakanaToZenkakuHiragana", zenkakuKatakanaToZenkakuHiragana))
 		}
-		return converters, nil
+		return converters
 	}
 	if options.optc {
-		converters = append(converters, ZenkakuKatakanaToZenkakuHiragana)
+		converters = append(converters, statelessKanaConverterStage("ZenkakuKatakanaToZenkakuHiragana", zenkakuKatakanaToZenkakuHiragana))
 	}
 	if options.optC {
-		converters = append(converters, ZenkakuHiraganaToZenkakuKatakana)
+		converters = append(converters, statelessKanaConverterStage("ZenkakuHiraganaToZenkakuKatakana", zenkakuHiraganaToZenkakuKatakana))
 	}
-	return converters, nil
+	return converters
 }
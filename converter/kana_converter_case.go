@@ -0,0 +1,139 @@
+package converter
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// CaseMode is a case conversion modeled on mb_convert_case of PHP. Letters are
+// mapped one to one by the simple case mappings of Unicode, so hankaku and
+// zenkaku Latin letters are converted alike and keep their width.
+type CaseMode int
+
+const (
+	// CaseUpper converts letters to upper case, such as ａｂｃ to ＡＢＣ.
+	CaseUpper CaseMode = iota
+	// CaseLower converts letters to lower case, such as ABC to abc.
+	CaseLower
+	// CaseTitle converts the first letter of each word to title case and the
+	// others to lower case, such as hELLO ｗｏｒｌｄ to Hello Ｗｏｒｌｄ.
+	CaseTitle
+)
+
+var caseModeNames = []string{"upper", "lower", "title"}
+
+// ParseCaseMode returns the mode named "upper", "lower" or "title", in any
+// case.
+func ParseCaseMode(name string) (CaseMode, error) {
+	for i, n := range caseModeNames {
+		if strings.EqualFold(n, name) {
+			return CaseMode(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown case mode %q", name)
+}
+
+func (m CaseMode) String() string {
+	if m < 0 || int(m) >= len(caseModeNames) {
+		return fmt.Sprintf("CaseMode(%d)", int(m))
+	}
+	return caseModeNames[m]
+}
+
+// stageName is the name of the registered stage converting the case by m.
+func (m CaseMode) stageName() string {
+	return "case_" + m.String()
+}
+
+// the case stages are registered to be used in modes, such as
+// "r+case_lower", and run after the options like any registered stage
+func init() {
+	for i := range caseModeNames {
+		mode := CaseMode(i)
+		var conflicts []string
+		for j := range caseModeNames {
+			if j != i {
+				conflicts = append(conflicts, CaseMode(j).stageName())
+			}
+		}
+		if err := RegisterKanaConverterStage(KanaConverterStageDefinition{
+			Name:      mode.stageName(),
+			Conflicts: conflicts,
+			NewStage: func() KanaConverterStage {
+				return newCaseStage(mode)
+			},
+		}); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// ConvertCase converts the case of letters by mode.
+func ConvertCase(in <-chan KanaConverterRune, mode CaseMode) <-chan KanaConverterRune {
+	return convertForKanaConverter(newCaseStage(mode), in)
+}
+
+// CompileCaseConversion returns a KanaConverter converting the case of letters
+// by mode.
+func CompileCaseConversion(mode CaseMode) (*KanaConverter, error) {
+	if mode < 0 || int(mode) >= len(caseModeNames) {
+		return nil, fmt.Errorf("unknown case mode %v", mode)
+	}
+	return Compile("+" + mode.stageName())
+}
+
+func newCaseStage(mode CaseMode) KanaConverterStage {
+	switch mode {
+	case CaseUpper:
+		return KanaConverterFunc(func(dst []KanaConverterRune, r KanaConverterRune) []KanaConverterRune {
+			return appendCase(dst, r, unicode.ToUpper(r.Rune))
+		})
+	case CaseLower:
+		return KanaConverterFunc(func(dst []KanaConverterRune, r KanaConverterRune) []KanaConverterRune {
+			return appendCase(dst, r, unicode.ToLower(r.Rune))
+		})
+	}
+	return &titleCaseStage{}
+}
+
+// appendCase appends r, converted to c unless it is already c.
+func appendCase(dst []KanaConverterRune, r KanaConverterRune, c rune) []KanaConverterRune {
+	if c == r.Rune {
+		return append(dst, r)
+	}
+	return append(dst, r.convert(c))
+}
+
+// titleCaseStage remembers whether the last rune is in a word. A word is a
+// run of cased letters and digits, which may contain apostrophes and
+// combining marks, so kana and kanji end a word as spaces do.
+type titleCaseStage struct {
+	inWord bool
+}
+
+func isCaseIgnorable(r rune) bool {
+	return r == '\'' || r == '’' || r == '＇' || unicode.In(r, unicode.Mn, unicode.Me)
+}
+
+func (s *titleCaseStage) Push(dst []KanaConverterRune, r KanaConverterRune) []KanaConverterRune {
+	switch {
+	case unicode.IsUpper(r.Rune) || unicode.IsLower(r.Rune) || unicode.IsTitle(r.Rune):
+		c := unicode.ToTitle(r.Rune)
+		if s.inWord {
+			c = unicode.ToLower(r.Rune)
+		}
+		s.inWord = true
+		return appendCase(dst, r, c)
+	case unicode.IsDigit(r.Rune):
+		s.inWord = true
+	case !isCaseIgnorable(r.Rune):
+		s.inWord = false
+	}
+	return append(dst, r)
+}
+
+func (s *titleCaseStage) Flush(dst []KanaConverterRune) []KanaConverterRune {
+	s.inWord = false
+	return dst
+}
@@ -0,0 +1,124 @@
+package converter_test
+
+import (
+	"testing"
+
+	"github.com/ArmadaSuit/udf-go/converter"
+)
+
+func TestConvertCase(t *testing.T) {
+	type args struct {
+		in   string
+		mode string
+	}
+	tests := []struct {
+		name string
+		args args
+		want string
+	}{
+		{
+			name: "upper",
+			args: args{in: "abc ＡｂＣ ｘｙｚ àéσ 123 あア漢", mode: "+case_upper"},
+			want: "ABC ＡＢＣ ＸＹＺ ÀÉΣ 123 あア漢",
+		},
+		{
+			name: "lower",
+			args: args{in: "ABC ＡｂＣ ＸＹＺ ÀÉΣ 123 あア漢", mode: "+case_lower"},
+			want: "abc ａｂｃ ｘｙｚ àéσ 123 あア漢",
+		},
+		{
+			name: "title",
+			args: args{in: "hELLO ｗｏｒｌｄ o'neil 1st ＡＢＣ　ｄｅｆ", mode: "+case_title"},
+			want: "Hello Ｗｏｒｌｄ O'neil 1st Ａｂｃ　Ｄｅｆ",
+		},
+		{
+			name: "title ends a word at kana and kanji",
+			args: args{in: "東京tower、ｐｏｐカルチャー", mode: "+case_title"},
+			want: "東京Tower、Ｐｏｐカルチャー",
+		},
+		{
+			name: "title starts over for each conversion",
+			args: args{in: "ab", mode: "+case_title"},
+			want: "Ab",
+		},
+		{
+			name: "after r",
+			args: args{in: "ＡｂＣ abc", mode: "r+case_lower"},
+			want: "abc abc",
+		},
+		{
+			name: "after R",
+			args: args{in: "ＡｂＣ abc", mode: "R+case_upper"},
+			want: "ＡＢＣ ＡＢＣ",
+		},
+		{
+			name: "after a",
+			args: args{in: "ｔｏｋｙｏ　ｔｏｗｅｒ", mode: "as+case_title"},
+			want: "Tokyo Tower",
+		},
+		{
+			name: "after A",
+			args: args{in: "Tokyo 2023", mode: "AS+case_upper"},
+			want: "ＴＯＫＹＯ　２０２３",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+
+			t.Parallel()
+
+			c, err := converter.Compile(tt.args.mode)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// twice to see that nothing is left from the first conversion
+			for i := 0; i < 2; i++ {
+				if got := c.Convert(tt.args.in); got != tt.want {
+					t.Errorf("%v is converted %v, want %v", tt.args.in, got, tt.want)
+				}
+			}
+			if got, _ := convertByChannel(tt.args.in, tt.args.mode); got != tt.want {
+				t.Errorf("%v is converted %v by channels, want %v", tt.args.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCaseMode(t *testing.T) {
+	type args struct {
+		name string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    converter.CaseMode
+		wantErr bool
+	}{
+		{name: "upper", args: args{name: "upper"}, want: converter.CaseUpper},
+		{name: "lower in upper case", args: args{name: "LOWER"}, want: converter.CaseLower},
+		{name: "title", args: args{name: "Title"}, want: converter.CaseTitle},
+		{name: "unknown", args: args{name: "fold"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+
+			t.Parallel()
+
+			got, err := converter.ParseCaseMode(tt.args.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseCaseMode(%v) error = %v, wantErr %v", tt.args.name, err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("%v is parsed %v, want %v", tt.args.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCaseConflicts(t *testing.T) {
+	if _, err := converter.Compile("+case_upper+case_lower"); err == nil {
+		t.Error("upper and lower are combined")
+	}
+}
@@ -0,0 +1,141 @@
+package converter
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// KanaClass is a set of character classes which text is checked against.
+type KanaClass uint
+
+const (
+	// KanaClassHiragana is ぁ to ゖ, ゝ, ゞ and ー.
+	KanaClassHiragana KanaClass = 1 << iota
+	// KanaClassZenkakuKatakana is ァ to ヺ, ヽ, ヾ and ー.
+	KanaClassZenkakuKatakana
+	// KanaClassHankakuKatakana is ｦ to ﾝ, ｧ to ｯ, ｰ, ﾞ, ﾟ and ｡｢｣､･.
+	KanaClassHankakuKatakana
+	// KanaClassZenkakuAlphabet is Ａ to Ｚ and ａ to ｚ.
+	KanaClassZenkakuAlphabet
+	// KanaClassHankakuAlphabet is A to Z and a to z.
+	KanaClassHankakuAlphabet
+	// KanaClassZenkakuNumber is ０ to ９.
+	KanaClassZenkakuNumber
+	// KanaClassHankakuNumber is 0 to 9.
+	KanaClassHankakuNumber
+	// KanaClassZenkakuSpace is U+3000.
+	KanaClassZenkakuSpace
+	// KanaClassHankakuSpace is U+0020.
+	KanaClassHankakuSpace
+	// KanaClassZenkaku is the characters of width 2 by RuneWidth.
+	KanaClassZenkaku
+	// KanaClassHankaku is the characters of width 1 by RuneWidth.
+	KanaClassHankaku
+)
+
+var kanaClassNames = []string{
+	"hiragana", "zenkaku_katakana", "hankaku_katakana",
+	"zenkaku_alphabet", "hankaku_alphabet", "zenkaku_number", "hankaku_number",
+	"zenkaku_space", "hankaku_space", "zenkaku", "hankaku",
+}
+
+// ParseKanaClass returns the set of the classes named in s separated by
+// commas, such as "zenkaku_katakana,zenkaku_space".
+func ParseKanaClass(s string) (KanaClass, error) {
+	var c KanaClass
+	for _, name := range strings.Split(s, ",") {
+		name = strings.TrimSpace(name)
+		found := false
+		for i, n := range kanaClassNames {
+			if n == name {
+				c |= 1 << i
+				found = true
+				break
+			}
+		}
+		if !found {
+			return 0, fmt.Errorf("unknown character class %q", name)
+		}
+	}
+	return c, nil
+}
+
+func (c KanaClass) String() string {
+	var names []string
+	for i, n := range kanaClassNames {
+		if c&(1<<i) != 0 {
+			names = append(names, n)
+		}
+	}
+	return strings.Join(names, ",")
+}
+
+// CheckKanaClass reports whether every character of s is in one of the
+// classes of c. It is true for an empty s, and false for invalid UTF-8.
+func CheckKanaClass(s string, c KanaClass) bool {
+	for i, r := range s {
+		if isInvalidUTF8At(s, i, r) || !c.contains(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// contains reports whether r is in one of the classes of c, telling the
+// classes of kana, Latin letters, numbers and spaces by whether the stages
+// converting them convert r. ゔ, ゕ, ゖ, ヴ, ヵ and ヶ are told by themselves, as
+// 'C' and 'c' pass them through.
+func (c KanaClass) contains(r rune) bool {
+	for i := range kanaClassNames {
+		class := KanaClass(1 << i)
+		if c&class == 0 {
+			continue
+		}
+		var in bool
+		switch class {
+		case KanaClassHiragana:
+			in = r == 'ー' || r == 'ゔ' || r == 'ゕ' || r == 'ゖ' || isConvertedBy(zenkakuHiraganaToZenkakuKatakana, r)
+		case KanaClassZenkakuKatakana:
+			in = r == 'ー' || r == 'ヴ' || r == 'ヵ' || r == 'ヶ' || isConvertedBy(zenkakuKatakanaToZenkakuHiragana, r)
+		case KanaClassHankakuKatakana:
+			in = hankakuKatakanaToZenkakuKatakanaSimple(KanaConverterRune{Rune: r}).IsConverted
+		case KanaClassZenkakuAlphabet:
+			in = isConvertedBy(zenkakuEnglishToHankakuEnglish, r)
+		case KanaClassHankakuAlphabet:
+			in = isConvertedBy(hankakuEnglishToZenkakuEnglish, r)
+		case KanaClassZenkakuNumber:
+			in = isConvertedBy(zenkakuNumberToHankakuNumber, r)
+		case KanaClassHankakuNumber:
+			in = isConvertedBy(hankakuNumberToZenkakuNumber, r)
+		case KanaClassZenkakuSpace:
+			in = isConvertedBy(zenkakuSpaceToHankakuSpace, r)
+		case KanaClassHankakuSpace:
+			in = isConvertedBy(hankakuSpaceToZenkakuSpace, r)
+		case KanaClassZenkaku:
+			in = RuneWidth(r) == 2
+		case KanaClassHankaku:
+			in = RuneWidth(r) == 1
+		}
+		if in {
+			return true
+		}
+	}
+	return false
+}
+
+// isInvalidUTF8At reports whether r ranged at s[i] is an invalid byte rather
+// than U+FFFD itself.
+func isInvalidUTF8At(s string, i int, r rune) bool {
+	if r != utf8.RuneError {
+		return false
+	}
+	_, size := utf8.DecodeRuneInString(s[i:])
+	return size == 1
+}
+
+func isConvertedBy(f KanaConverterFunc, r rune) bool {
+	var buf [2]KanaConverterRune
+	out := f(buf[:0], KanaConverterRune{Rune: r})
+	return len(out) != 1 || out[0].IsConverted
+}
@@ -0,0 +1,61 @@
+package converter_test
+
+import (
+	"testing"
+
+	"github.com/ArmadaSuit/udf-go/converter"
+)
+
+func TestCheckKanaClass(t *testing.T) {
+	type args struct {
+		s       string
+		classes string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    bool
+		wantErr bool
+	}{
+		{name: "zenkaku katakana", args: args{s: "ヤマダタロウ", classes: "zenkaku_katakana"}, want: true},
+		{name: "zenkaku katakana with prolonged sound mark", args: args{s: "ヴァーチャル", classes: "zenkaku_katakana"}, want: true},
+		{name: "zenkaku katakana with hiragana", args: args{s: "ヤマダたろう", classes: "zenkaku_katakana"}, want: false},
+		{name: "zenkaku katakana with space", args: args{s: "ヤマダ　タロウ", classes: "zenkaku_katakana"}, want: false},
+		{name: "zenkaku katakana and space", args: args{s: "ヤマダ　タロウ", classes: "zenkaku_katakana, zenkaku_space"}, want: true},
+		{name: "hankaku katakana in zenkaku katakana", args: args{s: "ﾔﾏﾀﾞ", classes: "zenkaku_katakana"}, want: false},
+		{name: "hiragana", args: args{s: "やまだゝらーめん", classes: "hiragana"}, want: true},
+		{name: "hiragana with ゔ", args: args{s: "ゔぁいおりん", classes: "hiragana"}, want: true},
+		{name: "hiragana with kanji", args: args{s: "山だ", classes: "hiragana"}, want: false},
+		{name: "hankaku katakana", args: args{s: "ﾔﾏﾀﾞ ﾊﾟｰｸ･ｷﾞﾝｺｳ", classes: "hankaku_katakana,hankaku_space"}, want: true},
+		{name: "symbol not in the classes", args: args{s: "ｶ)ABC ﾔﾏﾀﾞ1", classes: "hankaku_katakana,hankaku_alphabet,hankaku_number,hankaku_space"}, want: false},
+		{name: "alphabet and number", args: args{s: "ＡＢＣａｂｃ１２３", classes: "zenkaku_alphabet,zenkaku_number"}, want: true},
+		{name: "hankaku alphabet in zenkaku alphabet", args: args{s: "ＡＢC", classes: "zenkaku_alphabet"}, want: false},
+		{name: "hankaku number", args: args{s: "0123456789", classes: "hankaku_number"}, want: true},
+		{name: "hankaku", args: args{s: "ABC ﾔﾏﾀﾞ 123!", classes: "hankaku"}, want: true},
+		{name: "hankaku with zenkaku", args: args{s: "ABCア", classes: "hankaku"}, want: false},
+		{name: "zenkaku", args: args{s: "山田　タロウ１２３！", classes: "zenkaku"}, want: true},
+		{name: "zenkaku with hankaku katakana", args: args{s: "山田ﾀﾛｳ", classes: "zenkaku"}, want: false},
+		{name: "empty", args: args{s: "", classes: "hiragana"}, want: true},
+		{name: "invalid utf-8", args: args{s: "a\xff", classes: "hankaku"}, want: false},
+		{name: "replacement character", args: args{s: "�", classes: "hankaku"}, want: true},
+		{name: "unknown class", args: args{s: "a", classes: "hankaku,kanji"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+
+			t.Parallel()
+
+			c, err := converter.ParseKanaClass(tt.args.classes)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseKanaClass() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := converter.CheckKanaClass(tt.args.s, c); got != tt.want {
+				t.Errorf("%v is checked %v by %v, want %v", tt.args.s, got, c, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package converter
+
+import (
+	"strconv"
+	"strings"
+)
+
+// CompatCategory is a category of compatibility characters expanded to their
+// plain equivalents.
+type CompatCategory int
+
+const (
+	// CompatEnclosed is the enclosed numerals, letters and katakana, such as ①
+	// to 1, ⑴ to (1), ⒈ to 1., Ⓐ to A and ㋐ to ア.
+	CompatEnclosed CompatCategory = iota
+	// CompatSquared is the squared katakana words and ideographs, such as ㌔ to
+	// キロ, ㍿ to 株式会社, ㍻ to 平成 and ㋀ to 1月.
+	CompatSquared
+	// CompatUnits is the squared units, such as ㎏ to kg and ㎡ to m2.
+	CompatUnits
+	// CompatRoman is the Roman numerals, such as Ⅻ to XII.
+	CompatRoman
+	// CompatIdeographs is the parenthesized and circled ideographs, such as ㈱ to
+	// (株) and ㊤ to 上.
+	CompatIdeographs
+)
+
+var compatCategoryNames = []string{"enclosed", "squared", "units", "roman", "ideographs"}
+
+func (c CompatCategory) String() string {
+	if c < 0 || int(c) >= len(compatCategoryNames) {
+		return "CompatCategory(" + strconv.Itoa(int(c)) + ")"
+	}
+	return compatCategoryNames[c]
+}
+
+// compatStageName is the name of the registered stage expanding all the
+// categories, also enabled by the letter compatStageLetter.
+const (
+	compatStageName   = "compat"
+	compatStageLetter = 'X'
+)
+
+// stageName is the name of the registered stage expanding c.
+func (c CompatCategory) stageName() string {
+	return compatStageName + "_" + c.String()
+}
+
+// the compatibility stages are registered to be used in modes, such as
+// "KV+compat_units" or "KVX"
+func init() {
+	all := make([]CompatCategory, len(compatCategoryNames))
+	for i := range all {
+		all[i] = CompatCategory(i)
+		category := all[i]
+		if err := RegisterKanaConverterStage(KanaConverterStageDefinition{
+			Name:      category.stageName(),
+			Conflicts: []string{compatStageName},
+			NewStage: func() KanaConverterStage {
+				return compatExpander(category)
+			},
+		}); err != nil {
+			panic(err)
+		}
+	}
+	if err := RegisterKanaConverterStage(KanaConverterStageDefinition{
+		Name:   compatStageName,
+		Letter: compatStageLetter,
+		NewStage: func() KanaConverterStage {
+			return compatExpander(all...)
+		},
+	}); err != nil {
+		panic(err)
+	}
+}
+
+// ExpandCompat expands the compatibility characters of categories, or of all
+// the categories if none is given.
+func ExpandCompat(in <-chan KanaConverterRune, categories ...CompatCategory) <-chan KanaConverterRune {
+	if len(categories) == 0 {
+		for i := range compatCategoryNames {
+			categories = append(categories, CompatCategory(i))
+		}
+	}
+	return convertForKanaConverter(compatExpander(categories...), in)
+}
+
+func compatExpander(categories ...CompatCategory) KanaConverterFunc {
+	return func(dst []KanaConverterRune, r KanaConverterRune) []KanaConverterRune {
+		for _, c := range categories {
+			if s, ok := compatExpansions[c][r.Rune]; ok {
+				return appendKanaConverterSpan(dst, []KanaConverterRune{r}, s)
+			}
+		}
+		return append(dst, r)
+	}
+}
+
+// compatExpansions maps the characters of each category to the NFKC forms of
+// them, except that the units are written with / instead of U+2215.
+var compatExpansions = map[CompatCategory]map[rune]string{
+	CompatEnclosed: func() map[rune]string {
+		m := map[rune]string{'⓪': "0"}
+		for i := 0; i < 20; i++ {
+			n := strconv.Itoa(i + 1)
+			m['①'+rune(i)] = n
+			m['⑴'+rune(i)] = "(" + n + ")"
+			m['⒈'+rune(i)] = n + "."
+		}
+		for i := 0; i < 15; i++ {
+			m['㉑'+rune(i)] = strconv.Itoa(i + 21)
+			m['㊱'+rune(i)] = strconv.Itoa(i + 36)
+		}
+		for i := 0; i < 26; i++ {
+			m['⒜'+rune(i)] = "(" + string('a'+rune(i)) + ")"
+			m['Ⓐ'+rune(i)] = string('A' + rune(i))
+			m['ⓐ'+rune(i)] = string('a' + rune(i))
+		}
+		for i, r := range []rune("アイウエオカキクケコサシスセソタチツテトナニヌネノハヒフヘホマミムメモヤユヨラリルレロワヰヱヲ") {
+			m['㋐'+rune(i)] = string(r)
+		}
+		return m
+	}(),
+	CompatSquared: func() map[rune]string {
+		m := compatSequence('㌀', strings.Fields(`
+			アパート アルファ アンペア アール イニング インチ ウォン エスクード
+			エーカー オンス オーム カイリ カラット カロリー ガロン ガンマ
+			ギガ ギニー キュリー ギルダー キロ キログラム キロメートル キロワット
+			グラム グラムトン クルゼイロ クローネ ケース コルナ コーポ サイクル
+			サンチーム シリング センチ セント ダース デシ ドル トン
+			ナノ ノット ハイツ パーセント パーツ バーレル ピアストル ピクル
+			ピコ ビル ファラッド フィート ブッシェル フラン ヘクタール ペソ
+			ペニヒ ヘルツ ペンス ページ ベータ ポイント ボルト ホン
+			ポンド ホール ホーン マイクロ マイル マッハ マルク マンション
+			ミクロン ミリ ミリバール メガ メガトン メートル ヤード ヤール
+			ユアン リットル リラ ルピー ルーブル レム レントゲン ワット
+		`))
+		for r, s := range map[rune]string{'㍻': "平成", '㍼': "昭和", '㍽': "大正", '㍾': "明治", '㋿': "令和", '㍿': "株式会社"} {
+			m[r] = s
+		}
+		for i := 0; i < 12; i++ {
+			m['㋀'+rune(i)] = strconv.Itoa(i+1) + "月"
+		}
+		for i := 0; i < 31; i++ {
+			m['㏠'+rune(i)] = strconv.Itoa(i+1) + "日"
+		}
+		for i := 0; i < 25; i++ {
+			m['㍘'+rune(i)] = strconv.Itoa(i) + "点"
+		}
+		return m
+	}(),
+	CompatUnits: func() map[rune]string {
+		m := compatSequence('㍱', strings.Fields(`hPa da AU bar oV pc dm dm2 dm3 IU`))
+		for r, s := range compatSequence('㎀', strings.Fields(`
+			pA nA μA mA kA KB MB GB cal kcal pF nF μF μg mg kg
+			Hz kHz MHz GHz THz μl ml dl kl fm nm μm mm cm km mm2
+			cm2 m2 km2 mm3 cm3 m3 km3 m/s m/s2 Pa kPa MPa GPa rad rad/s rad/s2
+			ps ns μs ms pV nV μV mV kV MV pW nW μW mW kW MW
+			kΩ MΩ a.m. Bq cc cd C/kg Co. dB Gy ha HP in KK KM kt
+			lm ln log lx mb mil mol PH p.m. PPM PR sr Sv Wb V/m A/m
+		`)) {
+			m[r] = s
+		}
+		for r, s := range compatSequence('㋌', strings.Fields(`Hg erg eV LTD`)) {
+			m[r] = s
+		}
+		m['㏿'] = "gal"
+		return m
+	}(),
+	CompatRoman: func() map[rune]string {
+		numerals := strings.Fields(`I II III IV V VI VII VIII IX X XI XII L C D M`)
+		m := compatSequence('Ⅰ', numerals)
+		for i, s := range numerals {
+			m['ⅰ'+rune(i)] = strings.ToLower(s)
+		}
+		return m
+	}(),
+	CompatIdeographs: func() map[rune]string {
+		m := map[rune]string{}
+		for i, r := range []rune("一二三四五六七八九十月火水木金土日株有社名特財祝労代呼学監企資協祭休自至") {
+			m['㈠'+rune(i)] = "(" + string(r) + ")"
+		}
+		for i, r := range []rune("一二三四五六七八九十月火水木金土日株有社名特財祝労秘男女適優印注項休写正上中下左右医宗学監企資協夜") {
+			m['㊀'+rune(i)] = string(r)
+		}
+		return m
+	}(),
+}
+
+// compatSequence maps the characters from first to the strings in order.
+func compatSequence(first rune, s []string) map[rune]string {
+	m := make(map[rune]string, len(s))
+	for i, e := range s {
+		m[first+rune(i)] = e
+	}
+	return m
+}
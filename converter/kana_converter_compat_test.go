@@ -0,0 +1,107 @@
+package converter_test
+
+import (
+	"testing"
+
+	"github.com/ArmadaSuit/udf-go/converter"
+)
+
+func TestExpandCompat(t *testing.T) {
+	type args struct {
+		in   string
+		mode string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "all by letter",
+			args: args{in: "①㈱㍿㌔㎏Ⅻ㊤", mode: "X"},
+			want: "1(株)株式会社キロkgXII上",
+		},
+		{
+			name: "all by name",
+			args: args{in: "⑳⑴⒈⓪㉑㊿Ⓐⓩ⒜㋐", mode: "+compat"},
+			want: "20(1)1.02150Az(a)ア",
+		},
+		{
+			name: "enclosed",
+			args: args{in: "①㈱㌔㎏Ⅻ㊤", mode: "+compat_enclosed"},
+			want: "1㈱㌔㎏Ⅻ㊤",
+		},
+		{
+			name: "squared",
+			args: args{in: "①㍿㌔㍻㋿㋀㏾㍘㎏", mode: "+compat_squared"},
+			want: "①株式会社キロ平成令和1月31日0点㎏",
+		},
+		{
+			name: "units",
+			args: args{in: "㎏㎡㎧㏄㎕㏿㌔", mode: "+compat_units"},
+			want: "kgm2m/sccμlgal㌔",
+		},
+		{
+			name: "roman",
+			args: args{in: "ⅠⅫⅿⅳ①", mode: "+compat_roman"},
+			want: "IXIImiv①",
+		},
+		{
+			name: "ideographs",
+			args: args{in: "㈱㈠㉃㊤㊰①", mode: "+compat_ideographs"},
+			want: "(株)(一)(至)上夜①",
+		},
+		{
+			name: "categories combined",
+			args: args{in: "①㎏Ⅻ", mode: "+compat_enclosed+compat_roman"},
+			want: "1㎏XII",
+		},
+		{
+			name: "after the options",
+			args: args{in: "ｶﾞ①", mode: "KVX"},
+			want: "ガ1",
+		},
+		{
+			name:    "all and a category",
+			args:    args{mode: "X+compat_units"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+
+			t.Parallel()
+
+			c, err := converter.Compile(tt.args.mode)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Compile() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+			if got := c.Convert(tt.args.in); got != tt.want {
+				t.Errorf("%v is converted %v, want %v", tt.args.in, got, tt.want)
+			}
+			if got, _ := convertByChannel(tt.args.in, tt.args.mode); got != tt.want {
+				t.Errorf("%v is converted %v by channels, want %v", tt.args.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpandCompatOffsets(t *testing.T) {
+	c, err := converter.Compile("X")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, offsets := c.ConvertWithOffsets("a㍿")
+	want := converter.KanaConverterSpan{Start: 1, End: 4}
+	for _, o := range offsets[1:] {
+		if got := o.Input; got != want {
+			t.Errorf("input of %v = %v, want %v", o, got, want)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package converter
+
+import (
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// KanaConverter is a compiled conversion mode. It is safe for concurrent use
+// by multiple goroutines.
+type KanaConverter struct {
+	mode      string
+	newStages []kanaConverterStageBuilder
+	// table replaces the first folded stages when they can be folded
+	table  *kanaConverterTable
+	folded int
+	// pool keeps the stages for reuse, relying on Flush to reset them
+	pool sync.Pool
+}
+
+type kanaConverterState struct {
+	stages   []KanaConverterStage
+	src, tmp []KanaConverterRune
+}
+
+// Compile parses mode once and returns a KanaConverter that can be reused for
+// any number of conversions.
+func Compile(mode string) (*KanaConverter, error) {
+	options, err := NewKanaConverterOptions(mode)
+	if err != nil {
+		return nil, err
+	}
+	c := &KanaConverter{mode: mode, newStages: newKanaConverterStages(options)}
+	// fold as many stages from the start as possible, leaving the others to
+	// run after the table
+	stages := c.stages()
+	n := 0
+	for n < len(c.newStages) && c.newStages[n].foldable {
+		n++
+	}
+	for ; n > 0; n-- {
+		if table, ok := newKanaConverterTable(stages[:n]); ok {
+			c.table, c.folded = table, n
+			break
+		}
+	}
+	c.pool.New = func() interface{} {
+		return &kanaConverterState{stages: c.stages()}
+	}
+	return c, nil
+}
+
+// stages returns a fresh set of stages for a single conversion.
+func (c *KanaConverter) stages() []KanaConverterStage {
+	stages := make([]KanaConverterStage, 0, len(c.newStages)-c.folded+1)
+	if c.table != nil {
+		stages = append(stages, &kanaConverterTableStage{table: c.table})
+	}
+	for _, b := range c.newStages[c.folded:] {
+		stages = append(stages, b.newStage())
+	}
+	return stages
+}
+
+// String returns the mode the converter was compiled from.
+func (c *KanaConverter) String() string {
+	return c.mode
+}
+
+// Convert returns s converted by the compiled mode. Invalid UTF-8 is
+// replaced with U+FFFD.
+func (c *KanaConverter) Convert(s string) string {
+	out, _ := c.ConvertWithPolicy(s, ReplaceInvalidUTF8)
+	return out
+}
+
+// ConvertWithPolicy returns s converted by the compiled mode, handling invalid
+// UTF-8 by policy.
+func (c *KanaConverter) ConvertWithPolicy(s string, policy InvalidUTF8Policy) (string, error) {
+	if policy == RejectInvalidUTF8 {
+		if err := validateUTF8String(s); err != nil {
+			return "", err
+		}
+	}
+
+	st := c.pool.Get().(*kanaConverterState)
+	defer c.pool.Put(st)
+
+	out := st.run(appendKanaConverterRunes(st.src[:0], s))
+
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range out {
+		if policy == PassInvalidUTF8 && isInvalidUTF8Rune(r) {
+			b.WriteByte(s[r.Start])
+			continue
+		}
+		b.WriteRune(r.Rune)
+	}
+	return b.String(), nil
+}
+
+// ConvertBytes returns b converted by the compiled mode in a new slice.
+func (c *KanaConverter) ConvertBytes(b []byte) []byte {
+	return c.AppendConvert(make([]byte, 0, len(b)), b)
+}
+
+// AppendConvert appends src converted by the compiled mode to dst and returns
+// the extended slice. Invalid UTF-8 is replaced with U+FFFD.
+func (c *KanaConverter) AppendConvert(dst, src []byte) []byte {
+	dst, _ = c.AppendConvertWithPolicy(dst, src, ReplaceInvalidUTF8)
+	return dst
+}
+
+// AppendConvertWithPolicy is AppendConvert handling invalid UTF-8 by policy.
+// dst is returned unchanged on error.
+func (c *KanaConverter) AppendConvertWithPolicy(dst, src []byte, policy InvalidUTF8Policy) ([]byte, error) {
+	if policy == RejectInvalidUTF8 {
+		if err := validateUTF8(src); err != nil {
+			return dst, err
+		}
+	}
+
+	st := c.pool.Get().(*kanaConverterState)
+	defer c.pool.Put(st)
+
+	for _, r := range st.run(appendKanaConverterBytes(st.src[:0], src, 0)) {
+		if policy == PassInvalidUTF8 && isInvalidUTF8Rune(r) {
+			dst = append(dst, src[r.Start])
+			continue
+		}
+		dst = utf8.AppendRune(dst, r.Rune)
+	}
+	return dst, nil
+}
+
+func (st *kanaConverterState) run(src []KanaConverterRune) []KanaConverterRune {
+	out, spare := runKanaConverterStages(st.stages, src, st.tmp, true)
+	st.src, st.tmp = out, spare
+	return out
+}
@@ -0,0 +1,215 @@
+package converter_test
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ArmadaSuit/udf-go/converter"
+)
+
+func TestCompile(t *testing.T) {
+	type args struct {
+		in   string
+		mode string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "hankaku katakana -> zenkaku katakana with voiced mark",
+			args: args{in: "ﾎﾞｰﾙﾍﾟﾝ", mode: "KV"},
+			want: "ボールペン",
+		},
+		{
+			name: "all of english, number and space",
+			args: args{in: "「ﾎﾞｰﾙﾍﾟﾝの芯の太さは、０．７ｍｍです。」　", mode: "KVas"},
+			want: "「ボールペンの芯の太さは、0.7mmです。」 ",
+		},
+		{
+			name: "dangling hankaku katakana at the end",
+			args: args{in: "ｶﾞｷ", mode: "HV"},
+			want: "がき",
+		},
+		{
+			name:    "invalid option",
+			args:    args{mode: "kK"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+
+			t.Parallel()
+
+			c, err := converter.Compile(tt.args.mode)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Compile() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+			if got := c.String(); got != tt.args.mode {
+				t.Errorf("String() = %v, want %v", got, tt.args.mode)
+			}
+			// twice to make sure that no state is left over from the previous conversion
+			for i := 0; i < 2; i++ {
+				if got := c.Convert(tt.args.in); got != tt.want {
+					t.Errorf("%v is converted %v, want %v", tt.args.in, got, tt.want)
+				}
+				if got := string(c.ConvertBytes([]byte(tt.args.in))); got != tt.want {
+					t.Errorf("%v is converted %v by ConvertBytes, want %v", tt.args.in, got, tt.want)
+				}
+				if got := string(c.AppendConvert([]byte("prefix:"), []byte(tt.args.in))); got != "prefix:"+tt.want {
+					t.Errorf("%v is converted %v by AppendConvert, want %v", tt.args.in, got, "prefix:"+tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestKanaConverterConcurrent(t *testing.T) {
+	c, err := converter.Compile("KVas")
+	if err != nil {
+		t.Fatal(err)
+	}
+	in := "「ﾎﾞｰﾙﾍﾟﾝの芯の太さは、０．７ｍｍです。」ｶ"
+	want := "「ボールペンの芯の太さは、0.7mmです。」カ"
+
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if got := c.Convert(in); got != want {
+					t.Errorf("%v is converted %v, want %v", in, got, want)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestCompileSameAsStages(t *testing.T) {
+	var b strings.Builder
+	for _, rng := range [][2]rune{{0x0000, 0x00FF}, {0x3000, 0x30FF}, {0xFF00, 0xFFEF}} {
+		for r := rng[0]; r <= rng[1]; r++ {
+			for _, s := range []string{"", "ﾞ", "ﾟ", "ﾞﾟ"} {
+				b.WriteRune(r)
+				b.WriteString(s)
+				b.WriteString("ｶ")
+				b.WriteRune(r)
+				b.WriteString(s)
+				b.WriteString("ﾊ")
+			}
+		}
+	}
+	in := b.String()
+
+	for _, mode := range testModes {
+		mode := mode
+		t.Run(mode, func(t *testing.T) {
+
+			t.Parallel()
+
+			c, err := converter.Compile(mode)
+			if err != nil {
+				return
+			}
+			want := converter.ConvertForKanaConverter(in, mustNewKanaConverterStages(t, mode))
+			if got := c.Convert(in); got != want {
+				t.Errorf("Convert() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func BenchmarkKanaConverter(b *testing.B) {
+	c, err := converter.Compile("KVas")
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			c.Convert(benchmarkInput)
+		}
+	})
+}
+
+func TestCompileStatefulStages(t *testing.T) {
+	type args struct {
+		ins  []string
+		mode string
+	}
+	tests := []struct {
+		name  string
+		args  args
+		wants []string
+	}{
+		{
+			name:  "hankaku katakana held for a voiced mark",
+			args:  args{ins: []string{"ｶ", "ﾞ"}, mode: "KV"},
+			wants: []string{"カ", "゛"},
+		},
+		{
+			name:  "romaji after which a hyphen is a prolonged sound mark",
+			args:  args{ins: []string{"ka", "-a"}, mode: "+romaji_to_kana"},
+			wants: []string{"か", "-あ"},
+		},
+		{
+			name:  "kana held for a romaji syllable",
+			args:  args{ins: []string{"き", "ゃ"}, mode: "+romaji_hepburn"},
+			wants: []string{"ki", "xya"},
+		},
+		{
+			name:  "katakana before a dash",
+			args:  args{ins: []string{"カ", "-"}, mode: "+dash_context"},
+			wants: []string{"カ", "-"},
+		},
+		{
+			name:  "title case within a word",
+			args:  args{ins: []string{"ab", "cd"}, mode: "+case_title"},
+			wants: []string{"Ab", "Cd"},
+		},
+		{
+			name:  "kanji numeral held for the following digits",
+			args:  args{ins: []string{"二十", "二"}, mode: "j"},
+			wants: []string{"20", "2"},
+		},
+		{
+			name:  "arabic numeral held for the following digits",
+			args:  args{ins: []string{"1", "2"}, mode: "J"},
+			wants: []string{"一", "二"},
+		},
+		{
+			name:  "normalization segment",
+			args:  args{ins: []string{"か", "\u3099"}, mode: "+norm_nfc"},
+			wants: []string{"か", "\u3099"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+
+			t.Parallel()
+
+			c, err := converter.Compile(tt.args.mode)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// the stages of a conversion are reused by the next one
+			for i, in := range tt.args.ins {
+				if got := c.Convert(in); got != tt.wants[i] {
+					t.Errorf("%v is converted %v, want %v", in, got, tt.wants[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package converter_test
+
+import (
+	"testing"
+
+	"github.com/ArmadaSuit/udf-go/converter"
+)
+
+// TestKanaCoverage covers the kana added to Unicode after the ones of JIS X
+// 0208, and the ones passed through on purpose.
+func TestKanaCoverage(t *testing.T) {
+	type args struct {
+		in   string
+		mode string
+	}
+	tests := []struct {
+		name string
+		args args
+		want string
+	}{
+		{
+			name: "k: ヴ, and small ヵ and ヶ passed through",
+			args: args{in: "ヴヵヶ", mode: "k"},
+			want: "ｳﾞヵヶ",
+		},
+		{
+			name: "k: ヷヸヹヺ passed through",
+			args: args{in: "ヷヸヹヺ", mode: "k"},
+			want: "ヷヸヹヺ",
+		},
+		{
+			name: "k: katakana phonetic extensions",
+			args: args{in: "ㇰㇱㇲㇳㇴㇵㇶㇷㇸㇹㇺㇻㇼㇽㇾㇿ", mode: "k"},
+			want: "ｸｼｽﾄﾇﾊﾋﾌﾍﾎﾑﾗﾘﾙﾚﾛ",
+		},
+		{
+			name: "k: combining voiced marks",
+			args: args{in: "カ\u3099ハ\u309a", mode: "k"},
+			want: "ｶﾞﾊﾟ",
+		},
+		{
+			name: "k: passed through",
+			args: args{in: "゠ヽヾヿゝゞゟ", mode: "k"},
+			want: "゠ヽヾヿゝゞゟ",
+		},
+		{
+			name: "h: ゔ, small ゕ and ゖ passed through",
+			args: args{in: "ゔゕゖ", mode: "h"},
+			want: "ゔゕゖ",
+		},
+		{
+			name: "h: combining voiced marks",
+			args: args{in: "か\u3099は\u309a", mode: "h"},
+			want: "ｶﾞﾊﾟ",
+		},
+		{
+			name: "h: passed through",
+			args: args{in: "ゝゞゟ゠ヽヾヿ", mode: "h"},
+			want: "ゝゞゟ゠ヽヾヿ",
+		},
+		{
+			name: "c: ヴ, small ヵ and ヶ passed through",
+			args: args{in: "ヴヵヶ", mode: "c"},
+			want: "ヴヵヶ",
+		},
+		{
+			name: "c: ヷヸヹヺ passed through",
+			args: args{in: "ヷヸヹヺ", mode: "c"},
+			want: "ヷヸヹヺ",
+		},
+		{
+			name: "c: passed through",
+			args: args{in: "゠ヿㇰㇱㇲㇳㇴㇵㇶㇷㇸㇹㇺㇻㇼㇽㇾㇿ・ー\u3099゛", mode: "c"},
+			want: "゠ヿㇰㇱㇲㇳㇴㇵㇶㇷㇸㇹㇺㇻㇼㇽㇾㇿ・ー\u3099゛",
+		},
+		{
+			name: "C: ゔ, small ゕ and ゖ passed through",
+			args: args{in: "ゔゕゖ", mode: "C"},
+			want: "ゔゕゖ",
+		},
+		{
+			name: "C: わ and combining voiced mark",
+			args: args{in: "わ\u3099", mode: "C"},
+			want: "ワ\u3099",
+		},
+		{
+			name: "C: passed through",
+			args: args{in: "ゟ・ー\u3099゛", mode: "C"},
+			want: "ゟ・ー\u3099゛",
+		},
+		{
+			name: "KV: ﾜﾞ, ｦﾞ and ｳﾞ",
+			args: args{in: "ﾜﾞｦﾞｳﾞ", mode: "KV"},
+			want: "ヷヺヴ",
+		},
+		{
+			name: "KV: ｲﾞ and ｴﾞ",
+			args: args{in: "ｲﾞｴﾞ", mode: "KV"},
+			want: "イ゛エ゛",
+		},
+		{
+			name: "KV: ﾜ and ｦ without voiced mark",
+			args: args{in: "ﾜｦﾟﾜ", mode: "KV"},
+			want: "ワヲ゜ワ",
+		},
+		{
+			name: "K: ﾜﾞ without V",
+			args: args{in: "ﾜﾞ", mode: "K"},
+			want: "ワ゛",
+		},
+		{
+			name: "HV: ｳﾞ",
+			args: args{in: "ｳﾞｳ", mode: "HV"},
+			want: "ゔう",
+		},
+		{
+			name: "HV: ﾜﾞ and ｦﾞ",
+			args: args{in: "ﾜﾞｦﾞ", mode: "HV"},
+			want: "わ゛を゛",
+		},
+		{
+			name: "KV before c",
+			args: args{in: "ﾜﾞｦﾞ", mode: "KVc"},
+			want: "ヷヺ",
+		},
+		{
+			name: "NKV: held base before a converted numeral",
+			args: args{in: "ﾜ1ｶ1", mode: "NKV"},
+			want: "ワ１カ１",
+		},
+		{
+			name: "KVR: held base before a converted alphabet",
+			args: args{in: "ｦA", mode: "KVR"},
+			want: "ヲＡ",
+		},
+		{
+			name: "HVA: held base before a converted symbol",
+			args: args{in: "ｳ!", mode: "HVA"},
+			want: "う！",
+		},
+		{
+			name: "KVc: held base before a kept rune",
+			args: args{in: "ﾜヱ", mode: "KVc"},
+			want: "ワゑ",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+
+			t.Parallel()
+
+			c, err := converter.Compile(tt.args.mode)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := c.Convert(tt.args.in); got != tt.want {
+				t.Errorf("%v is converted %v, want %v", tt.args.in, got, tt.want)
+			}
+			stages, err := converter.NewKanaConverterStages(tt.args.mode)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := converter.ConvertForKanaConverter(tt.args.in, stages); got != tt.want {
+				t.Errorf("%v is converted %v by stages, want %v", tt.args.in, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package converter
+
+// dashStageName is the name of the registered stage normalizing dashes.
+const dashStageName = "dash_context"
+
+// the dash stage is registered to be used in modes, such as "KV+dash_context"
+func init() {
+	if err := RegisterKanaConverterStage(KanaConverterStageDefinition{
+		Name: dashStageName,
+		NewStage: func() KanaConverterStage {
+			return &dashStage{}
+		},
+	}); err != nil {
+		panic(err)
+	}
+}
+
+// NormalizeDashes normalizes dashes, hyphens, minus signs and prolonged sound
+// marks by the runes around them. A hyphen, a minus sign or a prolonged sound
+// mark alone between katakana and kana is the prolonged sound mark of the width
+// of the katakana, so a hyphen mistyped as in ラ-メン is ー, while dashes, such
+// as ― and —, are kept as punctuation, and so are hyphens before other runes,
+// such as - of ゴルフ-1 and ルート-A. Between digits or Latin letters, such as
+// 03ー1234 and ＡＢ－１, all of them are hankaku hyphen-minus. The others are
+// kept, such as ―― of そうだ―― and -- of ラ--メン.
+func NormalizeDashes(in <-chan KanaConverterRune) <-chan KanaConverterRune {
+	return convertForKanaConverter(&dashStage{}, in)
+}
+
+func isDash(r rune) bool {
+	switch r {
+	case '-', '‐', '‑', '‒', '–', '—', '―', '−', '－', 'ー', 'ｰ':
+		return true
+	}
+	return false
+}
+
+// isDashHyphen reports whether r is a dash which may be mistyped for the
+// prolonged sound mark.
+func isDashHyphen(r rune) bool {
+	switch r {
+	case '-', '‐', '‑', '−', '－', 'ー', 'ｰ':
+		return true
+	}
+	return false
+}
+
+func isDashKatakana(r rune) bool {
+	return (r >= 'ァ' && r <= 'ヺ') || (r >= 'ヽ' && r <= 'ヾ') || (r >= 'ㇰ' && r <= 'ㇿ') ||
+		(r >= 'ｦ' && r <= 'ﾟ')
+}
+
+func isDashKana(r rune) bool {
+	return isDashKatakana(r) || (r >= 'ぁ' && r <= 'ゖ') || r == 'ゝ' || r == 'ゞ'
+}
+
+func isDashAlphanumeric(r rune) bool {
+	return (r >= '0' && r <= '9') || (r >= 'A' && r <= 'Z') || (r >= 'a' && r <= 'z') ||
+		(r >= '０' && r <= '９') || (r >= 'Ａ' && r <= 'Ｚ') || (r >= 'ａ' && r <= 'ｚ')
+}
+
+// dashStage holds dashes until the next rune tells whether they are between
+// katakana and kana, or between digits or Latin letters.
+type dashStage struct {
+	// before is the last rune which is not a dash, or 0 at the start
+	before rune
+	dashes []KanaConverterRune
+}
+
+func (s *dashStage) Push(dst []KanaConverterRune, r KanaConverterRune) []KanaConverterRune {
+	if !isDash(r.Rune) {
+		dst = s.resolve(dst, r.Rune)
+		s.before = r.Rune
+		return append(dst, r)
+	}
+	s.dashes = append(s.dashes, r)
+	return dst
+}
+
+func (s *dashStage) Flush(dst []KanaConverterRune) []KanaConverterRune {
+	dst = s.resolve(dst, 0)
+	s.before = 0
+	return dst
+}
+
+// resolve writes the dashes held, followed by after.
+func (s *dashStage) resolve(dst []KanaConverterRune, after rune) []KanaConverterRune {
+	if len(s.dashes) == 1 && isDashHyphen(s.dashes[0].Rune) && isDashKatakana(s.before) && isDashKana(after) {
+		d := s.dashes[0]
+		s.dashes = s.dashes[:0]
+		mark := 'ー'
+		if s.before >= 'ｦ' && s.before <= 'ﾟ' {
+			mark = 'ｰ'
+		}
+		if d.Rune != mark {
+			d = d.convert(mark)
+		}
+		return append(dst, d)
+	}
+
+	between := isDashAlphanumeric(s.before) && isDashAlphanumeric(after)
+	for _, d := range s.dashes {
+		if between && d.Rune != '-' {
+			d = d.convert('-')
+		}
+		dst = append(dst, d)
+	}
+	s.dashes = s.dashes[:0]
+	return dst
+}
@@ -0,0 +1,81 @@
+package converter_test
+
+import (
+	"testing"
+
+	"github.com/ArmadaSuit/udf-go/converter"
+)
+
+func TestNormalizeDashes(t *testing.T) {
+	type args struct {
+		in   string
+		mode string
+	}
+	tests := []struct {
+		name string
+		args args
+		want string
+	}{
+		{
+			name: "between digits",
+			args: args{in: "03‐1234‑5678、100−2、〒100ー0001、1―2、3－4、5ｰ6", mode: "+dash_context"},
+			want: "03-1234-5678、100-2、〒100-0001、1-2、3-4、5-6",
+		},
+		{
+			name: "between latin letters",
+			args: args{in: "AB–12、ＡＢ—１、x--y", mode: "+dash_context"},
+			want: "AB-12、ＡＢ-１、x--y",
+		},
+		{
+			name: "between katakana and kana",
+			args: args{in: "ラ-メン、コ－ヒ−を、らーめん、ｺ-ﾋｰ、ヴァ‐チャル、ラｰメン、ｺーﾋ", mode: "+dash_context"},
+			want: "ラーメン、コーヒーを、らーめん、ｺｰﾋｰ、ヴァーチャル、ラーメン、ｺｰﾋ",
+		},
+		{
+			name: "hyphens after katakana before other runes are kept",
+			args: args{in: "ゴルフ-1、ページ−1、ルート-A、ラ--メン、ヴァ‐、カ-", mode: "+dash_context"},
+			want: "ゴルフ-1、ページ−1、ルート-A、ラ--メン、ヴァ‐、カ-",
+		},
+		{
+			name: "prose dashes after katakana are kept",
+			args: args{in: "コーヒー――、ア—イ、ア–イ、ア‒イ、カ-―", mode: "+dash_context"},
+			want: "コーヒー――、ア—イ、ア–イ、ア‒イ、カ-―",
+		},
+		{
+			name: "dashes after hiragana are kept",
+			args: args{in: "そうだ――、あ—い、ら-めん", mode: "+dash_context"},
+			want: "そうだ――、あ—い、ら-めん",
+		},
+		{
+			name: "others are kept",
+			args: args{in: "―東京―、- a、漢字ー、A—", mode: "+dash_context"},
+			want: "―東京―、- a、漢字ー、A—",
+		},
+		{
+			name: "after other stages",
+			args: args{in: "ｺ-ﾋｰ", mode: "KV+dash_context"},
+			want: "コーヒー",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+
+			t.Parallel()
+
+			c, err := converter.Compile(tt.args.mode)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// twice to see that nothing is left from the first conversion
+			for i := 0; i < 2; i++ {
+				if got := c.Convert(tt.args.in); got != tt.want {
+					t.Errorf("%v is converted %v, want %v", tt.args.in, got, tt.want)
+				}
+			}
+			if got, _ := convertByChannel(tt.args.in, tt.args.mode); got != tt.want {
+				t.Errorf("%v is converted %v by channels, want %v", tt.args.in, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,260 @@
+package converter
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+var (
+	// ErrJapaneseDateFormat is the error of a text which is not a date.
+	ErrJapaneseDateFormat = errors.New("invalid date format")
+	// ErrJapaneseDateRange is the error of a date which does not exist, such as
+	// 2月30日, or is out of its era, such as 平成32年.
+	ErrJapaneseDateRange = errors.New("date out of range")
+)
+
+// japaneseEra is an era from its first day to the first day of the next one.
+type japaneseEra struct {
+	name         string
+	abbreviation rune
+	squared      rune
+	start        time.Time
+}
+
+var japaneseEras = []japaneseEra{
+	{name: "明治", abbreviation: 'M', squared: '㍾', start: time.Date(1868, 10, 23, 0, 0, 0, 0, time.UTC)},
+	{name: "大正", abbreviation: 'T', squared: '㍽', start: time.Date(1912, 7, 30, 0, 0, 0, 0, time.UTC)},
+	{name: "昭和", abbreviation: 'S', squared: '㍼', start: time.Date(1926, 12, 25, 0, 0, 0, 0, time.UTC)},
+	{name: "平成", abbreviation: 'H', squared: '㍻', start: time.Date(1989, 1, 8, 0, 0, 0, 0, time.UTC)},
+	{name: "令和", abbreviation: 'R', squared: '㋿', start: time.Date(2019, 5, 1, 0, 0, 0, 0, time.UTC)},
+}
+
+// gregorianAdoption is 明治6年1月1日, when Japan adopted the Gregorian
+// calendar. The dates before it are not supported, as they were written in the
+// lunisolar calendar.
+var gregorianAdoption = time.Date(1873, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// japaneseDateMode converts zenkaku digits, letters and spaces to hankaku
+// before a date is parsed.
+const japaneseDateMode = "nrs"
+
+var (
+	japaneseDateOnce      sync.Once
+	japaneseDateConverter *KanaConverter
+)
+
+// ParseJapaneseDate parses a date written in wareki or in the Gregorian
+// calendar, such as 令和５年１０月１日, R5.10.1, H.31/4/30, 平成元年1月8日,
+// ２０２３年１０月１日 and 2023-10-01, and returns it at midnight in UTC.
+// Eras are written in kanji, such as 令和 or 令, in squared characters, such
+// as ㋿, or in Latin letters, such as R or R., and the first year of an era
+// may be 元年. The date must be in its era, so 平成31年5月1日 is an error.
+func ParseJapaneseDate(s string) (time.Time, error) {
+	japaneseDateOnce.Do(func() {
+		c, err := Compile(japaneseDateMode)
+		if err != nil {
+			panic(err)
+		}
+		japaneseDateConverter = c
+	})
+	p := &japaneseDateParser{s: []rune(strings.TrimSpace(japaneseDateConverter.Convert(s)))}
+
+	era, wareki := p.era()
+	var year int
+	if wareki && p.accept("元") {
+		year = 1
+	} else {
+		digits := 4
+		if wareki {
+			digits = 3
+		}
+		var ok bool
+		if year, ok = p.number(digits); !ok || (!wareki && p.n != 4) {
+			return time.Time{}, fmt.Errorf("%w: %q", ErrJapaneseDateFormat, s)
+		}
+	}
+
+	var month, day int
+	var ok bool
+	p.spaces()
+	if p.accept("年") {
+		if month, ok = p.field("月"); ok {
+			day, ok = p.field("日")
+		}
+	} else if sep, found := p.separator(); found {
+		if month, ok = p.number(2); ok && p.accept(string(sep)) {
+			day, ok = p.number(2)
+		} else {
+			ok = false
+		}
+	}
+	if !ok || p.i != len(p.s) {
+		return time.Time{}, fmt.Errorf("%w: %q", ErrJapaneseDateFormat, s)
+	}
+
+	if wareki {
+		if year < 1 {
+			return time.Time{}, fmt.Errorf("%w: %q", ErrJapaneseDateRange, s)
+		}
+		year += japaneseEras[era].start.Year() - 1
+	}
+	t := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	if month < 1 || month > 12 || day < 1 || t.Day() != day {
+		return time.Time{}, fmt.Errorf("%w: %q", ErrJapaneseDateRange, s)
+	}
+	if t.Before(gregorianAdoption) {
+		return time.Time{}, fmt.Errorf("%w: %q is before the Gregorian calendar", ErrJapaneseDateRange, s)
+	}
+	if wareki && japaneseEraOf(t) != era {
+		return time.Time{}, fmt.Errorf("%w: %q is not in %v", ErrJapaneseDateRange, s, japaneseEras[era].name)
+	}
+	return t, nil
+}
+
+// japaneseDateParser reads a date normalized by japaneseDateMode.
+type japaneseDateParser struct {
+	s []rune
+	i int
+	// n is the number of the digits read last
+	n int
+}
+
+func (p *japaneseDateParser) spaces() {
+	for p.i < len(p.s) && unicode.IsSpace(p.s[p.i]) {
+		p.i++
+	}
+}
+
+func (p *japaneseDateParser) accept(s string) bool {
+	r := []rune(s)
+	if len(p.s)-p.i < len(r) || string(p.s[p.i:p.i+len(r)]) != s {
+		return false
+	}
+	p.i += len(r)
+	return true
+}
+
+// era reads an era and reports false if the date is not in wareki, skipping
+// 西暦 written before a Gregorian year.
+func (p *japaneseDateParser) era() (int, bool) {
+	if p.accept("西暦") {
+		p.spaces()
+		return 0, false
+	}
+	for i, e := range japaneseEras {
+		name := []rune(e.name)
+		switch {
+		case p.accept(e.name), p.accept(string(e.squared)), p.accept(string(name[0])):
+		case p.i < len(p.s) && unicode.ToUpper(p.s[p.i]) == e.abbreviation:
+			p.i++
+			if !p.accept(".") {
+				p.accept("．")
+			}
+		default:
+			continue
+		}
+		p.spaces()
+		return i, true
+	}
+	return 0, false
+}
+
+// number reads up to max digits.
+func (p *japaneseDateParser) number(max int) (int, bool) {
+	start := p.i
+	for p.i < len(p.s) && p.i-start < max && p.s[p.i] >= '0' && p.s[p.i] <= '9' {
+		p.i++
+	}
+	p.n = p.i - start
+	if p.n == 0 {
+		return 0, false
+	}
+	n, err := strconv.Atoi(string(p.s[start:p.i]))
+	return n, err == nil
+}
+
+// field reads a number of up to 2 digits followed by unit.
+func (p *japaneseDateParser) field(unit string) (int, bool) {
+	p.spaces()
+	n, ok := p.number(2)
+	p.spaces()
+	return n, ok && p.accept(unit)
+}
+
+// separator reads a separator of a numeric date.
+func (p *japaneseDateParser) separator() (rune, bool) {
+	if p.i < len(p.s) && strings.ContainsRune("-/.－／．", p.s[p.i]) {
+		p.i++
+		return p.s[p.i-1], true
+	}
+	return 0, false
+}
+
+// japaneseEraOf returns the index of the era of t.
+func japaneseEraOf(t time.Time) int {
+	era := -1
+	for i, e := range japaneseEras {
+		if !t.Before(e.start) {
+			era = i
+		}
+	}
+	return era
+}
+
+// WarekiStyle is a way of writing a date in wareki.
+type WarekiStyle int
+
+const (
+	// WarekiLong writes the era and the units in kanji, such as 令和5年10月1日
+	// and 令和元年5月1日.
+	WarekiLong WarekiStyle = iota
+	// WarekiShort writes the era in a Latin letter and separates the numbers
+	// with dots, such as R5.10.1.
+	WarekiShort
+)
+
+var warekiStyleNames = []string{"long", "short"}
+
+// ParseWarekiStyle returns the style named "long" or "short".
+func ParseWarekiStyle(name string) (WarekiStyle, error) {
+	for i, n := range warekiStyleNames {
+		if n == name {
+			return WarekiStyle(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown wareki style %q", name)
+}
+
+func (s WarekiStyle) String() string {
+	if s < 0 || int(s) >= len(warekiStyleNames) {
+		return fmt.Sprintf("WarekiStyle(%d)", int(s))
+	}
+	return warekiStyleNames[s]
+}
+
+// FormatWareki writes the date of t in wareki by style. The dates before
+// 明治6年, when Japan adopted the Gregorian calendar, are an error.
+func FormatWareki(t time.Time, style WarekiStyle) (string, error) {
+	d := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	if d.Before(gregorianAdoption) {
+		return "", fmt.Errorf("%w: %v is before the Gregorian calendar", ErrJapaneseDateRange, d.Format("2006-01-02"))
+	}
+	e := japaneseEras[japaneseEraOf(d)]
+	year := d.Year() - e.start.Year() + 1
+	switch style {
+	case WarekiLong:
+		y := strconv.Itoa(year)
+		if year == 1 {
+			y = "元"
+		}
+		return fmt.Sprintf("%v%v年%d月%d日", e.name, y, d.Month(), d.Day()), nil
+	case WarekiShort:
+		return fmt.Sprintf("%c%d.%d.%d", e.abbreviation, year, d.Month(), d.Day()), nil
+	}
+	return "", fmt.Errorf("unknown wareki style %v", style)
+}
@@ -0,0 +1,104 @@
+package converter_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ArmadaSuit/udf-go/converter"
+)
+
+func TestParseJapaneseDate(t *testing.T) {
+	type args struct {
+		s string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    string
+		wantErr error
+	}{
+		{name: "zenkaku wareki", args: args{s: "令和５年１０月１日"}, want: "2023-10-01"},
+		{name: "abbreviation", args: args{s: "R5.10.1"}, want: "2023-10-01"},
+		{name: "abbreviation with dot", args: args{s: "H.31/4/30"}, want: "2019-04-30"},
+		{name: "lower case abbreviation", args: args{s: "s64-1-7"}, want: "1989-01-07"},
+		{name: "zenkaku abbreviation", args: args{s: "Ｈ３１．４．３０"}, want: "2019-04-30"},
+		{name: "kanji abbreviation", args: args{s: "平31年4月30日"}, want: "2019-04-30"},
+		{name: "squared era", args: args{s: "㋿元年5月1日"}, want: "2019-05-01"},
+		{name: "first year", args: args{s: "令和元年５月１日"}, want: "2019-05-01"},
+		{name: "first year in digits", args: args{s: "平成1年1月8日"}, want: "1989-01-08"},
+		{name: "spaces", args: args{s: " 平成 31 年 4 月 30 日 "}, want: "2019-04-30"},
+		{name: "taisho", args: args{s: "大正元年7月30日"}, want: "1912-07-30"},
+		{name: "meiji", args: args{s: "明治6年1月1日"}, want: "1873-01-01"},
+		{name: "gregorian", args: args{s: "２０２３年１０月１日"}, want: "2023-10-01"},
+		{name: "gregorian with seireki", args: args{s: "西暦2023年10月1日"}, want: "2023-10-01"},
+		{name: "iso", args: args{s: "2023-10-01"}, want: "2023-10-01"},
+		{name: "slashes", args: args{s: "2023/1/2"}, want: "2023-01-02"},
+		{name: "leap day", args: args{s: "令和2年2月29日"}, want: "2020-02-29"},
+		{name: "after the end of heisei", args: args{s: "平成31年5月1日"}, wantErr: converter.ErrJapaneseDateRange},
+		{name: "before the start of reiwa", args: args{s: "令和元年4月30日"}, wantErr: converter.ErrJapaneseDateRange},
+		{name: "before the start of heisei", args: args{s: "H1.1.7"}, wantErr: converter.ErrJapaneseDateRange},
+		{name: "year 0", args: args{s: "令和0年1月1日"}, wantErr: converter.ErrJapaneseDateRange},
+		{name: "no such day", args: args{s: "令和5年2月29日"}, wantErr: converter.ErrJapaneseDateRange},
+		{name: "no such month", args: args{s: "2023-13-01"}, wantErr: converter.ErrJapaneseDateRange},
+		{name: "lunisolar calendar", args: args{s: "明治5年12月2日"}, wantErr: converter.ErrJapaneseDateRange},
+		{name: "mixed separators", args: args{s: "2023-10/01"}, wantErr: converter.ErrJapaneseDateFormat},
+		{name: "two digit year", args: args{s: "23-10-01"}, wantErr: converter.ErrJapaneseDateFormat},
+		{name: "no day", args: args{s: "令和5年10月"}, wantErr: converter.ErrJapaneseDateFormat},
+		{name: "trailing text", args: args{s: "令和5年10月1日(日)"}, wantErr: converter.ErrJapaneseDateFormat},
+		{name: "unknown era", args: args{s: "X5.10.1"}, wantErr: converter.ErrJapaneseDateFormat},
+		{name: "empty", args: args{s: ""}, wantErr: converter.ErrJapaneseDateFormat},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+
+			t.Parallel()
+
+			got, err := converter.ParseJapaneseDate(tt.args.s)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ParseJapaneseDate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && got.Format("2006-01-02") != tt.want {
+				t.Errorf("%v is parsed %v, want %v", tt.args.s, got.Format("2006-01-02"), tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatWareki(t *testing.T) {
+	type args struct {
+		t     time.Time
+		style converter.WarekiStyle
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    string
+		wantErr bool
+	}{
+		{name: "long", args: args{t: time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC), style: converter.WarekiLong}, want: "令和5年10月1日"},
+		{name: "short", args: args{t: time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC), style: converter.WarekiShort}, want: "R5.10.1"},
+		{name: "first year", args: args{t: time.Date(2019, 5, 1, 0, 0, 0, 0, time.UTC), style: converter.WarekiLong}, want: "令和元年5月1日"},
+		{name: "first year short", args: args{t: time.Date(2019, 5, 1, 0, 0, 0, 0, time.UTC), style: converter.WarekiShort}, want: "R1.5.1"},
+		{name: "last day of heisei", args: args{t: time.Date(2019, 4, 30, 0, 0, 0, 0, time.UTC), style: converter.WarekiLong}, want: "平成31年4月30日"},
+		{name: "last day of showa", args: args{t: time.Date(1989, 1, 7, 0, 0, 0, 0, time.UTC), style: converter.WarekiShort}, want: "S64.1.7"},
+		{name: "date in the location", args: args{t: time.Date(2019, 5, 1, 0, 30, 0, 0, time.FixedZone("JST", 9*60*60)), style: converter.WarekiLong}, want: "令和元年5月1日"},
+		{name: "lunisolar calendar", args: args{t: time.Date(1872, 12, 31, 0, 0, 0, 0, time.UTC), style: converter.WarekiLong}, wantErr: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+
+			t.Parallel()
+
+			got, err := converter.FormatWareki(tt.args.t, tt.args.style)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FormatWareki() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("%v is formatted %v, want %v", tt.args.t, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package converter
+
+import (
+	"sort"
+	"strings"
+)
+
+// KanaConverterTrace reports a part of the input changed by a stage.
+type KanaConverterTrace struct {
+	Stage     string `json:"stage"`
+	Original  string `json:"original"`
+	Converted string `json:"converted"`
+	// Input and Output are byte offsets, InputRunes is rune indexes.
+	Input      KanaConverterSpan `json:"input"`
+	InputRunes KanaConverterSpan `json:"input_runes"`
+	Output     KanaConverterSpan `json:"output"`
+}
+
+// Explain returns s converted by the compiled mode together with a trace of
+// every part changed and the stage which changed it, in output order. A part
+// deleted by a stage is traced with an empty Converted and Output at where it
+// would have been.
+func (c *KanaConverter) Explain(s string) (string, []KanaConverterTrace) {
+	src := appendKanaConverterRunes(nil, s)
+	starts := make([]int, 0, len(src))
+	for _, r := range src {
+		starts = append(starts, r.Start)
+	}
+
+	var tmp []KanaConverterRune
+	var deletions []kanaConverterDeletion
+	for _, b := range c.newStages {
+		stage := b.newStage()
+		dst := tmp[:0]
+		for _, r := range src {
+			n := len(dst)
+			dst = stage.Push(dst, r)
+			markKanaConverterStage(dst[n:], b.name)
+		}
+		n := len(dst)
+		dst = stage.Flush(dst)
+		markKanaConverterStage(dst[n:], b.name)
+		deletions = appendKanaConverterDeletions(deletions, b.name, src, dst)
+		src, tmp = dst, src
+	}
+	sort.SliceStable(deletions, func(i, j int) bool {
+		return deletions[i].Start < deletions[j].Start
+	})
+
+	var out strings.Builder
+	out.Grow(len(s))
+	var traces []KanaConverterTrace
+	// a deletion is traced before the first rune following it in the input
+	deleted := func(end int) {
+		for len(deletions) > 0 && deletions[0].Start < end {
+			d := deletions[0]
+			deletions = deletions[1:]
+			at := KanaConverterSpan{Start: out.Len(), End: out.Len()}
+			if n := len(traces); n > 0 {
+				last := &traces[n-1]
+				if last.Stage == d.stage && last.Converted == "" && last.Input.End == d.Start && last.Output == at {
+					last.Original = s[last.Input.Start:d.End]
+					last.Input.End = d.End
+					last.InputRunes.End = sort.SearchInts(starts, d.End)
+					continue
+				}
+			}
+			traces = append(traces, KanaConverterTrace{
+				Stage:      d.stage,
+				Original:   s[d.Start:d.End],
+				Input:      d.KanaConverterSpan,
+				InputRunes: KanaConverterSpan{Start: sort.SearchInts(starts, d.Start), End: sort.SearchInts(starts, d.End)},
+				Output:     at,
+			})
+		}
+	}
+	for _, r := range src {
+		deleted(r.End)
+		start := out.Len()
+		out.WriteRune(r.Rune)
+		if !r.IsConverted {
+			continue
+		}
+		if n := len(traces); n > 0 {
+			last := &traces[n-1]
+			if last.Stage == r.Stage && last.Input == (KanaConverterSpan{Start: r.Start, End: r.End}) && last.Output.End == start {
+				last.Converted += string(r.Rune)
+				last.Output.End = out.Len()
+				continue
+			}
+		}
+		traces = append(traces, KanaConverterTrace{
+			Stage:      r.Stage,
+			Original:   s[r.Start:r.End],
+			Converted:  string(r.Rune),
+			Input:      KanaConverterSpan{Start: r.Start, End: r.End},
+			InputRunes: KanaConverterSpan{Start: sort.SearchInts(starts, r.Start), End: sort.SearchInts(starts, r.End)},
+			Output:     KanaConverterSpan{Start: start, End: out.Len()},
+		})
+	}
+	deleted(len(s) + 1)
+	return out.String(), traces
+}
+
+// kanaConverterDeletion is a part of the input deleted by a stage.
+type kanaConverterDeletion struct {
+	KanaConverterSpan
+	stage string
+}
+
+// appendKanaConverterDeletions appends the runes of src which no rune of dst
+// is converted from, as deleted by the stage named name.
+func appendKanaConverterDeletions(deletions []kanaConverterDeletion, name string, src, dst []KanaConverterRune) []kanaConverterDeletion {
+	i := 0
+	for _, r := range src {
+		for i < len(dst) && dst[i].End <= r.Start {
+			i++
+		}
+		if i < len(dst) && dst[i].Start <= r.Start {
+			continue
+		}
+		deletions = append(deletions, kanaConverterDeletion{KanaConverterSpan: KanaConverterSpan{Start: r.Start, End: r.End}, stage: name})
+	}
+	return deletions
+}
+
+// markKanaConverterStage sets name to the runes newly converted by a stage.
+func markKanaConverterStage(runes []KanaConverterRune, name string) {
+	for i := range runes {
+		if runes[i].IsConverted && runes[i].Stage == "" {
+			runes[i].Stage = name
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package converter_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ArmadaSuit/udf-go/converter"
+)
+
+func TestKanaConverterExplain(t *testing.T) {
+	registerTestStages()
+
+	type args struct {
+		in   string
+		mode string
+	}
+	type span = converter.KanaConverterSpan
+	tests := []struct {
+		name       string
+		args       args
+		want       string
+		wantTraces []converter.KanaConverterTrace
+	}{
+		{
+			name: "not converted",
+			args: args{in: "あいう", mode: "KV"},
+			want: "あいう",
+		},
+		{
+			name: "merged and converted by different stages",
+			args: args{in: "ｶﾞ１a", mode: "KVn"},
+			want: "ガ1a",
+			wantTraces: []converter.KanaConverterTrace{
+				{
+					Stage:      "HankakuKatakanaToZenkakuKatakana",
+					Original:   "ｶﾞ",
+					Converted:  "ガ",
+					Input:      span{Start: 0, End: 6},
+					InputRunes: span{Start: 0, End: 2},
+					Output:     span{Start: 0, End: 3},
+				},
+				{
+					Stage:      "ZenkakuNumberToHankakuNumber",
+					Original:   "１",
+					Converted:  "1",
+					Input:      span{Start: 6, End: 9},
+					InputRunes: span{Start: 2, End: 3},
+					Output:     span{Start: 3, End: 4},
+				},
+			},
+		},
+		{
+			name: "split",
+			args: args{in: "ガガ", mode: "k"},
+			want: "ｶﾞｶﾞ",
+			wantTraces: []converter.KanaConverterTrace{
+				{
+					Stage:      "ZenkakuKatakanaToHankakuKatakana",
+					Original:   "ガ",
+					Converted:  "ｶﾞ",
+					Input:      span{Start: 0, End: 3},
+					InputRunes: span{Start: 0, End: 1},
+					Output:     span{Start: 0, End: 6},
+				},
+				{
+					Stage:      "ZenkakuKatakanaToHankakuKatakana",
+					Original:   "ガ",
+					Converted:  "ｶﾞ",
+					Input:      span{Start: 3, End: 6},
+					InputRunes: span{Start: 1, End: 2},
+					Output:     span{Start: 6, End: 12},
+				},
+			},
+		},
+		{
+			name: "deleted",
+			args: args{in: "ｱ\u200b\u200bい\u200b", mode: "KV+registry_test_drop"},
+			want: "アい",
+			wantTraces: []converter.KanaConverterTrace{
+				{
+					Stage:      "HankakuKatakanaToZenkakuKatakana",
+					Original:   "ｱ",
+					Converted:  "ア",
+					Input:      span{Start: 0, End: 3},
+					InputRunes: span{Start: 0, End: 1},
+					Output:     span{Start: 0, End: 3},
+				},
+				{
+					Stage:      "registry_test_drop",
+					Original:   "\u200b\u200b",
+					Input:      span{Start: 3, End: 9},
+					InputRunes: span{Start: 1, End: 3},
+					Output:     span{Start: 3, End: 3},
+				},
+				{
+					Stage:      "registry_test_drop",
+					Original:   "\u200b",
+					Input:      span{Start: 12, End: 15},
+					InputRunes: span{Start: 4, End: 5},
+					Output:     span{Start: 6, End: 6},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+
+			t.Parallel()
+
+			c, err := converter.Compile(tt.args.mode)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, gotTraces := c.Explain(tt.args.in)
+			if got != tt.want {
+				t.Errorf("%v is converted %v, want %v", tt.args.in, got, tt.want)
+			}
+			if !reflect.DeepEqual(gotTraces, tt.wantTraces) {
+				t.Errorf("traces of %v = %+v, want %+v", tt.args.in, gotTraces, tt.wantTraces)
+			}
+		})
+	}
+}
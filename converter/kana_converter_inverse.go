@@ -0,0 +1,170 @@
+package converter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// String returns the mode of the options. Registered stages and mappings are
+// given by name, even when enabled by a letter.
+func (r *KanaConverterOptions) String() string {
+	var b strings.Builder
+	for _, flag := range kanaConverterBuiltinLetters {
+		if r.isEnabled(string(flag)) {
+			b.WriteRune(flag)
+		}
+	}
+	for _, s := range r.stages {
+		b.WriteString("+")
+		b.WriteString(s.name)
+	}
+	return b.String()
+}
+
+// Inverse returns the options undoing the conversions of r, such as 'k' for
+// 'K'. The katakana to hankaku options are undone with 'V' to compose the
+// voiced marks they split. Registered stages and mappings have no inverse.
+func (r *KanaConverterOptions) Inverse() (*KanaConverterOptions, error) {
+	if len(r.stages) > 0 {
+		return nil, fmt.Errorf("mode %q has no inverse: stage %q has no inverse", r, r.stages[0].name)
+	}
+	inverses := []struct {
+		enabled bool
+		enable  []func(*KanaConverterOptions) error
+	}{
+		{r.optr, []func(*KanaConverterOptions) error{(*KanaConverterOptions).EnableOptR}},
+		{r.optR, []func(*KanaConverterOptions) error{(*KanaConverterOptions).EnableOptr}},
+		{r.optn, []func(*KanaConverterOptions) error{(*KanaConverterOptions).EnableOptN}},
+		{r.optN, []func(*KanaConverterOptions) error{(*KanaConverterOptions).EnableOptn}},
+		{r.opta, []func(*KanaConverterOptions) error{(*KanaConverterOptions).EnableOptA}},
+		{r.optA, []func(*KanaConverterOptions) error{(*KanaConverterOptions).EnableOpta}},
+		{r.opts, []func(*KanaConverterOptions) error{(*KanaConverterOptions).EnableOptS}},
+		{r.optS, []func(*KanaConverterOptions) error{(*KanaConverterOptions).EnableOpts}},
+		{r.optk, []func(*KanaConverterOptions) error{(*KanaConverterOptions).EnableOptK, (*KanaConverterOptions).EnableOptV}},
+		{r.optK, []func(*KanaConverterOptions) error{(*KanaConverterOptions).EnableOptk}},
+		{r.opth, []func(*KanaConverterOptions) error{(*KanaConverterOptions).EnableOptH, (*KanaConverterOptions).EnableOptV}},
+		{r.optH, []func(*KanaConverterOptions) error{(*KanaConverterOptions).EnableOpth}},
+		{r.optc, []func(*KanaConverterOptions) error{(*KanaConverterOptions).EnableOptC}},
+		{r.optC, []func(*KanaConverterOptions) error{(*KanaConverterOptions).EnableOptc}},
+		{r.optM, []func(*KanaConverterOptions) error{(*KanaConverterOptions).EnableOptm}},
+		{r.optm, []func(*KanaConverterOptions) error{(*KanaConverterOptions).EnableOptM}},
+		{r.optj, []func(*KanaConverterOptions) error{(*KanaConverterOptions).EnableOptJ}},
+		{r.optJ, []func(*KanaConverterOptions) error{(*KanaConverterOptions).EnableOptj}},
+		{r.optD, []func(*KanaConverterOptions) error{(*KanaConverterOptions).EnableOptj}},
+	}
+	o := &KanaConverterOptions{}
+	for _, inverse := range inverses {
+		if !inverse.enabled {
+			continue
+		}
+		for _, enable := range inverse.enable {
+			if err := enable(o); err != nil {
+				return nil, fmt.Errorf("mode %q has no inverse: %w", r, err)
+			}
+		}
+	}
+	return o, nil
+}
+
+// KanaConverterLoss is a part of the input that is not restored by converting
+// it by a mode and then by the inverse of the mode.
+type KanaConverterLoss struct {
+	Original  string `json:"original"`
+	Converted string `json:"converted"`
+	RoundTrip string `json:"round_trip"`
+	// Input is byte offsets, InputRunes is rune indexes.
+	Input      KanaConverterSpan `json:"input"`
+	InputRunes KanaConverterSpan `json:"input_runes"`
+}
+
+// Inverse compiles the inverse of the mode of c.
+func (c *KanaConverter) Inverse() (*KanaConverter, error) {
+	options, err := NewKanaConverterOptions(c.mode)
+	if err != nil {
+		return nil, err
+	}
+	inverse, err := options.Inverse()
+	if err != nil {
+		return nil, err
+	}
+	return Compile(inverse.String())
+}
+
+// VerifyRoundTrip reports the parts of s that are not restored by converting
+// s by c and then by the inverse of c, in input order. It reports nothing if
+// the conversion is lossless for s.
+func (c *KanaConverter) VerifyRoundTrip(s string) ([]KanaConverterLoss, error) {
+	inverse, err := c.Inverse()
+	if err != nil {
+		return nil, err
+	}
+	converted, forward := c.ConvertWithOffsets(s)
+	roundTrip, backward := inverse.ConvertWithOffsets(converted)
+
+	// group the round trip runes by the part of s they come from
+	type group struct {
+		input, roundTrip KanaConverterSpan
+	}
+	var groups []group
+	appendGroup := func(in, out KanaConverterSpan) {
+		if n := len(groups); n > 0 && in.Start < groups[n-1].input.End {
+			last := &groups[n-1]
+			if in.End > last.input.End {
+				last.input.End = in.End
+			}
+			last.roundTrip.End = out.End
+			return
+		}
+		end := 0
+		if n := len(groups); n > 0 {
+			end = groups[n-1].input.End
+		}
+		if in.Start > end {
+			// the part of s between is removed
+			groups = append(groups, group{input: KanaConverterSpan{Start: end, End: in.Start}, roundTrip: KanaConverterSpan{Start: out.Start, End: out.Start}})
+		}
+		groups = append(groups, group{input: in, roundTrip: out})
+	}
+	for _, b := range backward {
+		in, ok := forward.Input(b.Input.Start, b.Input.End)
+		if !ok {
+			continue
+		}
+		appendGroup(in, b.Output)
+	}
+	appendGroup(KanaConverterSpan{Start: len(s), End: len(s)}, KanaConverterSpan{Start: len(roundTrip), End: len(roundTrip)})
+
+	starts := make([]int, 0, len(s))
+	for i := range s {
+		starts = append(starts, i)
+	}
+	var losses []KanaConverterLoss
+	f := 0
+	for _, g := range groups {
+		original := s[g.input.Start:g.input.End]
+		restored := roundTrip[g.roundTrip.Start:g.roundTrip.End]
+		// the converted runes of the group are the ones from its input
+		for f < len(forward) && forward[f].Input.Start < g.input.Start {
+			f++
+		}
+		out := KanaConverterSpan{Start: len(converted), End: len(converted)}
+		if f < len(forward) {
+			out = KanaConverterSpan{Start: forward[f].Output.Start, End: forward[f].Output.Start}
+		}
+		for ; f < len(forward) && forward[f].Input.Start < g.input.End; f++ {
+			out.End = forward[f].Output.End
+		}
+		if original == restored {
+			continue
+		}
+		losses = append(losses, KanaConverterLoss{
+			Original:   original,
+			Converted:  converted[out.Start:out.End],
+			RoundTrip:  restored,
+			Input:      g.input,
+			InputRunes: KanaConverterSpan{Start: sort.SearchInts(starts, g.input.Start), End: sort.SearchInts(starts, g.input.End)},
+		})
+	}
+	return losses, nil
+}
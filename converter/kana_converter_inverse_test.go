@@ -0,0 +1,164 @@
+package converter_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ArmadaSuit/udf-go/converter"
+)
+
+func TestKanaConverterOptionsInverse(t *testing.T) {
+	tests := []struct {
+		name    string
+		mode    string
+		want    string
+		wantErr bool
+	}{
+		{name: "empty", mode: "", want: ""},
+		{name: "english, number and space to hankaku", mode: "rns", want: "RNS"},
+		{name: "english, number and space to zenkaku", mode: "RNS", want: "rns"},
+		{name: "english and number", mode: "as", want: "AS"},
+		{name: "hankaku katakana to zenkaku katakana", mode: "KV", want: "k"},
+		{name: "zenkaku katakana to hankaku katakana", mode: "k", want: "KV"},
+		{name: "hankaku katakana to hiragana", mode: "HV", want: "h"},
+		{name: "hiragana to hankaku katakana", mode: "h", want: "HV"},
+		{name: "katakana to hiragana", mode: "c", want: "C"},
+		{name: "hiragana to katakana", mode: "C", want: "c"},
+		{name: "kanji numerals to arabic numerals", mode: "j", want: "J"},
+		{name: "arabic numerals to kanji numerals", mode: "D", want: "j"},
+		{name: "normalized order", mode: "VKa", want: "Ak"},
+		{name: "katakana and hiragana to hankaku", mode: "kh", wantErr: true},
+		{name: "hankaku katakana and hiragana to zenkaku katakana", mode: "KC", wantErr: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+
+			t.Parallel()
+
+			options, err := converter.NewKanaConverterOptions(tt.mode)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := options.Inverse()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Inverse() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+			if got.String() != tt.want {
+				t.Errorf("inverse of %v is %v, want %v", tt.mode, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKanaConverterOptionsString(t *testing.T) {
+	tests := []struct {
+		mode string
+		want string
+	}{
+		{mode: "", want: ""},
+		{mode: "KV", want: "KV"},
+		{mode: "VKas", want: "asKV"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.mode, func(t *testing.T) {
+
+			t.Parallel()
+
+			options, err := converter.NewKanaConverterOptions(tt.mode)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := options.String(); got != tt.want {
+				t.Errorf("String() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKanaConverterVerifyRoundTrip(t *testing.T) {
+	type args struct {
+		in   string
+		mode string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    []converter.KanaConverterLoss
+		wantErr bool
+	}{
+		{
+			name: "lossless",
+			args: args{in: "ｶﾞｰﾙﾌﾚﾝﾄﾞとﾎﾟｲﾝﾄ", mode: "KV"},
+		},
+		{
+			name: "zenkaku and hankaku prolonged sound marks are merged",
+			args: args{in: "ｶﾞｰﾙとー", mode: "KV"},
+			want: []converter.KanaConverterLoss{
+				{
+					Original:   "ー",
+					Converted:  "ー",
+					RoundTrip:  "ｰ",
+					Input:      converter.KanaConverterSpan{Start: 15, End: 18},
+					InputRunes: converter.KanaConverterSpan{Start: 5, End: 6},
+				},
+			},
+		},
+		{
+			name: "hankaku katakana is not kept",
+			args: args{in: "ガｶ", mode: "k"},
+			want: []converter.KanaConverterLoss{
+				{
+					Original:   "ｶ",
+					Converted:  "ｶ",
+					RoundTrip:  "カ",
+					Input:      converter.KanaConverterSpan{Start: 3, End: 6},
+					InputRunes: converter.KanaConverterSpan{Start: 1, End: 2},
+				},
+			},
+		},
+		{
+			name: "english",
+			args: args{in: "abｃ", mode: "R"},
+			want: []converter.KanaConverterLoss{
+				{
+					Original:   "ｃ",
+					Converted:  "ｃ",
+					RoundTrip:  "c",
+					Input:      converter.KanaConverterSpan{Start: 2, End: 5},
+					InputRunes: converter.KanaConverterSpan{Start: 2, End: 3},
+				},
+			},
+		},
+		{
+			name:    "no inverse",
+			args:    args{in: "ｶ", mode: "kh"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+
+			t.Parallel()
+
+			c, err := converter.Compile(tt.args.mode)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := c.VerifyRoundTrip(tt.args.in)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("VerifyRoundTrip() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("VerifyRoundTrip(%v) = %+v, want %+v", tt.args.in, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,226 @@
+package converter
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// KanaConverterMapping is a user-defined table mapping runes to strings. It
+// is a KanaConverterStage, and a mode refers to a registered mapping by name
+// after the options, such as "KV+house". Mappings run after the built-in
+// stages in the order they are named, together with registered stages.
+type KanaConverterMapping struct {
+	name    string
+	mapping map[rune][]rune
+}
+
+// NewKanaConverterMapping returns a mapping named name which replaces each key
+// of mapping with its value. An empty value removes the rune.
+func NewKanaConverterMapping(name string, mapping map[rune]string) (*KanaConverterMapping, error) {
+	if err := validateKanaConverterMappingName(name); err != nil {
+		return nil, err
+	}
+	m := &KanaConverterMapping{name: name, mapping: make(map[rune][]rune, len(mapping))}
+	for k, v := range mapping {
+		m.mapping[k] = []rune(v)
+	}
+	return m, nil
+}
+
+// ParseKanaConverterMappingTSV reads a mapping from r. Each line holds a rune
+// and the string it is mapped to separated by a tab, with Go escapes such as
+// \u200b allowed in both. Empty lines and lines starting with # are ignored.
+func ParseKanaConverterMappingTSV(name string, r io.Reader) (*KanaConverterMapping, error) {
+	mapping := make(map[rune]string)
+	s := bufio.NewScanner(r)
+	for line := 1; s.Scan(); line++ {
+		text := strings.TrimSuffix(s.Text(), "\r")
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Split(text, "\t")
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("mapping %q line %d: 2 fields separated by a tab expected", name, line)
+		}
+		from, err := unescapeKanaConverterMapping(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("mapping %q line %d: %w", name, line, err)
+		}
+		to, err := unescapeKanaConverterMapping(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("mapping %q line %d: %w", name, line, err)
+		}
+		k, err := kanaConverterMappingKey(from)
+		if err != nil {
+			return nil, fmt.Errorf("mapping %q line %d: %w", name, line, err)
+		}
+		mapping[k] = to
+	}
+	if err := s.Err(); err != nil {
+		return nil, fmt.Errorf("mapping %q: %w", name, err)
+	}
+	return NewKanaConverterMapping(name, mapping)
+}
+
+// ParseKanaConverterMappingJSON reads a mapping from r holding a JSON object,
+// such as {"〜": "ー", "\u200b": ""}.
+func ParseKanaConverterMappingJSON(name string, r io.Reader) (*KanaConverterMapping, error) {
+	var object map[string]string
+	if err := json.NewDecoder(r).Decode(&object); err != nil {
+		return nil, fmt.Errorf("mapping %q: %w", name, err)
+	}
+	mapping := make(map[rune]string, len(object))
+	for from, to := range object {
+		k, err := kanaConverterMappingKey(from)
+		if err != nil {
+			return nil, fmt.Errorf("mapping %q: %w", name, err)
+		}
+		mapping[k] = to
+	}
+	return NewKanaConverterMapping(name, mapping)
+}
+
+// LoadKanaConverterMapping reads a mapping from a .tsv or .json file. The
+// mapping is named after the file without the extension.
+func LoadKanaConverterMapping(path string) (*KanaConverterMapping, error) {
+	ext := filepath.Ext(path)
+	name := strings.TrimSuffix(filepath.Base(path), ext)
+	var parse func(string, io.Reader) (*KanaConverterMapping, error)
+	switch strings.ToLower(ext) {
+	case ".tsv":
+		parse = ParseKanaConverterMappingTSV
+	case ".json":
+		parse = ParseKanaConverterMappingJSON
+	default:
+		return nil, fmt.Errorf("mapping %q: unknown file extension %q: must be '.tsv' or '.json'", name, ext)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return parse(name, f)
+}
+
+// LoadKanaConverterMappings registers the mappings of every .tsv and .json
+// file in dir. Nothing is registered if any of them fails to load.
+func LoadKanaConverterMappings(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	var mappings []*KanaConverterMapping
+	for _, e := range entries {
+		switch strings.ToLower(filepath.Ext(e.Name())) {
+		case ".tsv", ".json":
+		default:
+			continue
+		}
+		if e.IsDir() {
+			continue
+		}
+		m, err := LoadKanaConverterMapping(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return err
+		}
+		mappings = append(mappings, m)
+	}
+	for _, m := range mappings {
+		RegisterKanaConverterMapping(m)
+	}
+	return nil
+}
+
+var kanaConverterMappings = struct {
+	sync.RWMutex
+	m map[string]*KanaConverterMapping
+}{m: make(map[string]*KanaConverterMapping)}
+
+// RegisterKanaConverterMapping makes m available to modes by its name,
+// replacing a mapping registered with the same name. Converters compiled
+// before keep the mapping they were compiled with.
+func RegisterKanaConverterMapping(m *KanaConverterMapping) {
+	kanaConverterMappings.Lock()
+	defer kanaConverterMappings.Unlock()
+	kanaConverterMappings.m[m.name] = m
+}
+
+func lookupKanaConverterMapping(name string) (*KanaConverterMapping, error) {
+	kanaConverterMappings.RLock()
+	defer kanaConverterMappings.RUnlock()
+	m, ok := kanaConverterMappings.m[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown mapping %q", name)
+	}
+	return m, nil
+}
+
+// Name returns the name of the mapping.
+func (m *KanaConverterMapping) Name() string {
+	return m.name
+}
+
+// Convert is the mapping as a converter to be composed with the ones returned
+// by NewKanaConverters.
+func (m *KanaConverterMapping) Convert(in <-chan KanaConverterRune) <-chan KanaConverterRune {
+	return convertForKanaConverter(m, in)
+}
+
+// Push maps r whether or not it is converted by an earlier stage, so that a
+// mapping also applies to the output of the options.
+func (m *KanaConverterMapping) Push(dst []KanaConverterRune, r KanaConverterRune) []KanaConverterRune {
+	to, ok := m.mapping[r.Rune]
+	if !ok {
+		return append(dst, r)
+	}
+	for _, c := range to {
+		dst = append(dst, r.convert(c))
+	}
+	return dst
+}
+
+func (m *KanaConverterMapping) Flush(dst []KanaConverterRune) []KanaConverterRune {
+	return dst
+}
+
+func validateKanaConverterMappingName(name string) error {
+	if name == "" {
+		return fmt.Errorf("mapping name must not be empty")
+	}
+	if strings.Contains(name, "+") {
+		return fmt.Errorf("mapping name %q must not contain '+'", name)
+	}
+	return nil
+}
+
+func kanaConverterMappingKey(s string) (rune, error) {
+	r, size := utf8.DecodeRuneInString(s)
+	if s == "" || size != len(s) || (r == utf8.RuneError && size == 1) {
+		return 0, fmt.Errorf("mapping key %q must be a single character", s)
+	}
+	return r, nil
+}
+
+func unescapeKanaConverterMapping(s string) (string, error) {
+	if !strings.Contains(s, `\`) {
+		return s, nil
+	}
+	var b strings.Builder
+	for s != "" {
+		r, _, tail, err := strconv.UnquoteChar(s, 0)
+		if err != nil {
+			return "", fmt.Errorf("invalid escape in %q", s)
+		}
+		b.WriteRune(r)
+		s = tail
+	}
+	return b.String(), nil
+}
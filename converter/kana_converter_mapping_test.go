@@ -0,0 +1,250 @@
+package converter_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ArmadaSuit/udf-go/converter"
+)
+
+func TestParseKanaConverterMappingTSV(t *testing.T) {
+	type args struct {
+		tsv string
+		in  string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "rune to rune, rune to string and removal",
+			args: args{tsv: "〜\tー\n㈱\t(株)\n\\u200b\t\n", in: "㈱テスト\u200b〜"},
+			want: "(株)テストー",
+		},
+		{
+			name: "comments, empty lines and CRLF",
+			args: args{tsv: "# house rules\r\n\r\n〜\tー\r\n", in: "〜"},
+			want: "ー",
+		},
+		{
+			name: "escaped tab",
+			args: args{tsv: "\\t\t \n", in: "a\tb"},
+			want: "a b",
+		},
+		{
+			name:    "key of 2 runes",
+			args:    args{tsv: "ab\tc\n"},
+			wantErr: true,
+		},
+		{
+			name:    "empty key",
+			args:    args{tsv: "\tc\n"},
+			wantErr: true,
+		},
+		{
+			name:    "no tab",
+			args:    args{tsv: "a\n"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid escape",
+			args:    args{tsv: "\\q\tc\n"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+
+			t.Parallel()
+
+			m, err := converter.ParseKanaConverterMappingTSV("test", strings.NewReader(tt.args.tsv))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseKanaConverterMappingTSV() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+			if got := converter.ConvertForKanaConverter(tt.args.in, []converter.KanaConverterStage{m}); got != tt.want {
+				t.Errorf("%v is converted %v, want %v", tt.args.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseKanaConverterMappingJSON(t *testing.T) {
+	type args struct {
+		json string
+		in   string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "rune to rune, rune to string and removal",
+			args: args{json: `{"〜": "ー", "㈱": "(株)", "\u200b": ""}`, in: "㈱テスト\u200b〜"},
+			want: "(株)テストー",
+		},
+		{
+			name:    "key of 2 runes",
+			args:    args{json: `{"ab": "c"}`},
+			wantErr: true,
+		},
+		{
+			name:    "not an object",
+			args:    args{json: `["a"]`},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+
+			t.Parallel()
+
+			m, err := converter.ParseKanaConverterMappingJSON("test", strings.NewReader(tt.args.json))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseKanaConverterMappingJSON() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+			if got := converter.ConvertForKanaConverter(tt.args.in, []converter.KanaConverterStage{m}); got != tt.want {
+				t.Errorf("%v is converted %v, want %v", tt.args.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadKanaConverterMappings(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"mapping_test_house.tsv":  "〜\tー\n㈱\t(株)\n\\u200b\t\n",
+		"mapping_test_dash.json":  `{"ー": "-"}`,
+		"mapping_test_ignored.md": "not a mapping",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := converter.LoadKanaConverterMappings(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	type args struct {
+		in   string
+		mode string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "mapping after options",
+			args: args{in: "ｶﾞｽ㈱\u200b〜", mode: "KV+mapping_test_house"},
+			want: "ガス(株)ー",
+		},
+		{
+			name: "mapping applies to the output of options",
+			args: args{in: "ﾎﾞｰﾙ", mode: "KV+mapping_test_dash"},
+			want: "ボ-ル",
+		},
+		{
+			name: "mappings in order",
+			args: args{in: "〜", mode: "+mapping_test_house+mapping_test_dash"},
+			want: "-",
+		},
+		{
+			name:    "unknown mapping",
+			args:    args{mode: "KV+mapping_test_ignored"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid option before a mapping",
+			args:    args{mode: "kK+mapping_test_house"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+
+			t.Parallel()
+
+			c, err := converter.Compile(tt.args.mode)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Compile() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+			if got := c.Convert(tt.args.in); got != tt.want {
+				t.Errorf("%v is converted %v, want %v", tt.args.in, got, tt.want)
+			}
+			stages, err := converter.NewKanaConverterStages(tt.args.mode)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := converter.ConvertForKanaConverter(tt.args.in, stages); got != tt.want {
+				t.Errorf("%v is converted %v by stages, want %v", tt.args.in, got, tt.want)
+			}
+			if got, _ := convertByChannel(tt.args.in, tt.args.mode); got != tt.want {
+				t.Errorf("%v is converted %v by channels, want %v", tt.args.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadKanaConverterMappingsError(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"mapping_test_valid.tsv":   "a\tb\n",
+		"mapping_test_invalid.tsv": "ab\tc\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := converter.LoadKanaConverterMappings(dir); err == nil {
+		t.Fatal("LoadKanaConverterMappings() error = nil, want error")
+	}
+	if _, err := converter.Compile("+mapping_test_valid"); err == nil {
+		t.Error("mapping_test_valid is registered though the directory failed to load")
+	}
+}
+
+func TestKanaConverterMappingWithNewKanaConverters(t *testing.T) {
+	m, err := converter.NewKanaConverterMapping("test", map[rune]string{'〜': "ー"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	converters, err := converter.NewKanaConverters("KV")
+	if err != nil {
+		t.Fatal(err)
+	}
+	converters = append(converters, m.Convert)
+
+	in := "ﾎﾞｰﾙ〜"
+	want := "ボールー"
+	c := converter.GenerateForKanaConverter(in)
+	for _, f := range converters {
+		c = f(c)
+	}
+	if got := converter.StringForKanaConverter(c); got != want {
+		t.Errorf("%v is converted %v, want %v", in, got, want)
+	}
+}
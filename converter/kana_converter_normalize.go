@@ -0,0 +1,111 @@
+package converter
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// NormalizationForm is a Unicode normalization form.
+type NormalizationForm int
+
+const (
+	NFC NormalizationForm = iota
+	NFD
+	NFKC
+	NFKD
+)
+
+var normalizationForms = []norm.Form{norm.NFC, norm.NFD, norm.NFKC, norm.NFKD}
+
+var normalizationFormNames = []string{"NFC", "NFD", "NFKC", "NFKD"}
+
+// ParseNormalizationForm returns the form named "NFC", "NFD", "NFKC" or
+// "NFKD", in any case.
+func ParseNormalizationForm(name string) (NormalizationForm, error) {
+	for i, n := range normalizationFormNames {
+		if strings.EqualFold(n, name) {
+			return NormalizationForm(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown normalization form %q", name)
+}
+
+func (f NormalizationForm) String() string {
+	if f < 0 || int(f) >= len(normalizationFormNames) {
+		return fmt.Sprintf("NormalizationForm(%d)", int(f))
+	}
+	return normalizationFormNames[f]
+}
+
+// Normalize returns s normalized to f. Invalid UTF-8 is copied untouched.
+func Normalize(s string, f NormalizationForm) string {
+	return normalizationForms[f].String(s)
+}
+
+// the normalization stages are registered to be used in modes, such as
+// "KV+norm_nfkc", named after the forms in lower case
+func init() {
+	var names []string
+	for _, n := range normalizationFormNames {
+		names = append(names, "norm_"+strings.ToLower(n))
+	}
+	for i, name := range names {
+		form := normalizationForms[i]
+		var conflicts []string
+		for _, n := range names {
+			if n != name {
+				conflicts = append(conflicts, n)
+			}
+		}
+		if err := RegisterKanaConverterStage(KanaConverterStageDefinition{
+			Name:      name,
+			Conflicts: conflicts,
+			NewStage: func() KanaConverterStage {
+				return &normalizeStage{form: form}
+			},
+		}); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// NormalizeKana normalizes the runes to f.
+func NormalizeKana(in <-chan KanaConverterRune, f NormalizationForm) <-chan KanaConverterRune {
+	return convertForKanaConverter(&normalizeStage{form: normalizationForms[f]}, in)
+}
+
+// normalizeStage holds the runes since the last normalization boundary, which
+// are normalized together.
+type normalizeStage struct {
+	form    norm.Form
+	segment []KanaConverterRune
+	b       strings.Builder
+}
+
+func (s *normalizeStage) Push(dst []KanaConverterRune, r KanaConverterRune) []KanaConverterRune {
+	if len(s.segment) > 0 && s.form.PropertiesString(string(r.Rune)).BoundaryBefore() {
+		dst = s.Flush(dst)
+	}
+	s.segment = append(s.segment, r)
+	return dst
+}
+
+func (s *normalizeStage) Flush(dst []KanaConverterRune) []KanaConverterRune {
+	if len(s.segment) == 0 {
+		return dst
+	}
+	segment := s.segment
+	s.segment = s.segment[:0]
+
+	s.b.Reset()
+	for _, r := range segment {
+		s.b.WriteRune(r.Rune)
+	}
+	in := s.b.String()
+	if s.form.IsNormalString(in) {
+		return append(dst, segment...)
+	}
+	return appendKanaConverterSpan(dst, segment, s.form.String(in))
+}
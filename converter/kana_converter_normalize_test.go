@@ -0,0 +1,195 @@
+package converter_test
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+
+	"github.com/ArmadaSuit/udf-go/converter"
+)
+
+// normalizationTestVersion is the version of testdata/NormalizationTest.txt,
+// the Unicode normalization conformance test data.
+const normalizationTestVersion = "15.0.0"
+
+type normalizationTestCase struct {
+	line    int
+	part    string
+	columns [5]string
+}
+
+func readNormalizationTest(t *testing.T) []normalizationTestCase {
+	t.Helper()
+
+	if norm.Version != normalizationTestVersion {
+		t.Skipf("the tables are of Unicode %v, not %v", norm.Version, normalizationTestVersion)
+	}
+	f, err := os.Open("testdata/NormalizationTest.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var cases []normalizationTestCase
+	part := ""
+	s := bufio.NewScanner(f)
+	for line := 1; s.Scan(); line++ {
+		text := s.Text()
+		if i := strings.IndexByte(text, '#'); i >= 0 {
+			text = text[:i]
+		}
+		text = strings.TrimSpace(text)
+		if text == "" {
+			continue
+		}
+		if strings.HasPrefix(text, "@") {
+			part = text
+			continue
+		}
+		fields := strings.Split(text, ";")
+		if len(fields) < 5 {
+			t.Fatalf("line %v: %v columns", line, len(fields))
+		}
+		c := normalizationTestCase{line: line, part: part}
+		for i := range c.columns {
+			var b strings.Builder
+			for _, cp := range strings.Fields(fields[i]) {
+				r, err := strconv.ParseUint(cp, 16, 32)
+				if err != nil {
+					t.Fatalf("line %v: %v", line, err)
+				}
+				b.WriteRune(rune(r))
+			}
+			c.columns[i] = b.String()
+		}
+		cases = append(cases, c)
+	}
+	if err := s.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return cases
+}
+
+// normalizationTestWants returns the column each column is normalized to by
+// form, as the conformance invariants of NormalizationTest.txt.
+func normalizationTestWants(form converter.NormalizationForm) [5]int {
+	switch form {
+	case converter.NFC:
+		return [5]int{1, 1, 1, 3, 3}
+	case converter.NFD:
+		return [5]int{2, 2, 2, 4, 4}
+	case converter.NFKC:
+		return [5]int{3, 3, 3, 3, 3}
+	}
+	return [5]int{4, 4, 4, 4, 4}
+}
+
+func TestNormalizeConformance(t *testing.T) {
+	cases := readNormalizationTest(t)
+	for _, form := range []converter.NormalizationForm{converter.NFC, converter.NFD, converter.NFKC, converter.NFKD} {
+		form := form
+		t.Run(form.String(), func(t *testing.T) {
+
+			t.Parallel()
+
+			c, err := converter.Compile("+norm_" + strings.ToLower(form.String()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			wants := normalizationTestWants(form)
+			for _, tc := range cases {
+				for i, in := range tc.columns {
+					want := tc.columns[wants[i]]
+					if got := converter.Normalize(in, form); got != want {
+						t.Errorf("line %v: c%v %+q is normalized %+q, want %+q", tc.line, i+1, in, got, want)
+					}
+					if got := c.Convert(in); got != want {
+						t.Errorf("line %v: c%v %+q is converted %+q, want %+q", tc.line, i+1, in, got, want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestNormalizeInvariance(t *testing.T) {
+	listed := map[rune]bool{}
+	for _, tc := range readNormalizationTest(t) {
+		if strings.HasPrefix(tc.part, "@Part1") {
+			r, _ := utf8.DecodeRuneInString(tc.columns[0])
+			listed[r] = true
+		}
+	}
+	for _, form := range []converter.NormalizationForm{converter.NFC, converter.NFD, converter.NFKC, converter.NFKD} {
+		for r := rune(0); r <= utf8.MaxRune; r++ {
+			if listed[r] || !utf8.ValidRune(r) {
+				continue
+			}
+			if got := converter.Normalize(string(r), form); got != string(r) {
+				t.Errorf("%U is normalized %+q by %v, want itself", r, got, form)
+			}
+		}
+	}
+}
+
+func TestNormalizeKana(t *testing.T) {
+	type args struct {
+		in   string
+		mode string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    string
+		wantErr bool
+	}{
+		{name: "nfkc", args: args{in: "ｶﾞｷﾞ①㍿ＡＢＣ", mode: "+norm_nfkc"}, want: "ガギ1株式会社ABC"},
+		{name: "nfc", args: args{in: "\u304b\u3099ｶﾞ", mode: "+norm_nfc"}, want: "\u304cｶﾞ"},
+		{name: "nfd", args: args{in: "\u304c", mode: "+norm_nfd"}, want: "\u304b\u3099"},
+		{name: "nfkd", args: args{in: "ｶﾞ", mode: "+norm_nfkd"}, want: "\u30ab\u3099"},
+		{name: "after the options", args: args{in: "ｶﾞ", mode: "KV+norm_nfd"}, want: "\u30ab\u3099"},
+		{name: "forms combined", args: args{mode: "+norm_nfc+norm_nfd"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+
+			t.Parallel()
+
+			c, err := converter.Compile(tt.args.mode)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Compile() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+			if got := c.Convert(tt.args.in); got != tt.want {
+				t.Errorf("%v is converted %+q, want %+q", tt.args.in, got, tt.want)
+			}
+			if got, _ := convertByChannel(tt.args.in, tt.args.mode); got != tt.want {
+				t.Errorf("%v is converted %+q by channels, want %+q", tt.args.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseNormalizationForm(t *testing.T) {
+	for _, name := range []string{"NFC", "nfd", "Nfkc", "NFKD"} {
+		form, err := converter.ParseNormalizationForm(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.EqualFold(form.String(), name) {
+			t.Errorf("ParseNormalizationForm(%v) = %v", name, form)
+		}
+	}
+	if _, err := converter.ParseNormalizationForm("NFX"); err == nil {
+		t.Error("ParseNormalizationForm(NFX) error = nil")
+	}
+}
@@ -0,0 +1,416 @@
+package converter
+
+import (
+	"strings"
+	"unicode"
+)
+
+var kanjiNumeralDigits = map[rune]uint64{
+	'〇': 0, '零': 0, '一': 1, '二': 2, '三': 3, '四': 4, '五': 5, '六': 6, '七': 7, '八': 8, '九': 9,
+}
+
+var kanjiNumeralSmallUnits = map[rune]uint64{
+	'十': 10, '百': 100, '千': 1000,
+}
+
+var kanjiNumeralLargeUnits = map[rune]uint64{
+	'万': 1_0000, '億': 1_0000_0000, '兆': 1_0000_0000_0000,
+}
+
+// kanjiNumeralLimit is the first number which has no kanji numeral up to 兆.
+const kanjiNumeralLimit = 1_0000_0000_0000_0000
+
+func arabicNumeralDigit(r rune) (uint64, bool) {
+	switch {
+	case r >= '0' && r <= '9':
+		return uint64(r - '0'), true
+	case r >= '０' && r <= '９':
+		return uint64(r - '０'), true
+	}
+	return 0, false
+}
+
+// kanjiNumeralCounters are the counters and units which a numeral joined to
+// kanji is converted before, such as 本 of 三本 and 丁 of 三丁目. 戸 is not one
+// of them, as 一戸 to 九戸 are the names of towns, such as 八戸.
+const kanjiNumeralCounters = "年月日時分秒週歳才人名個本枚冊台匹頭羽杯回度番号丁条階件円倍割点位着軒票問通社校章節巻話曲足"
+
+// kanjiNumeralSuffixes are the kanji which may follow a counter, such as 目 of
+// 三丁目.
+const kanjiNumeralSuffixes = "目度間半前後分以未頃程余弱強超"
+
+// kanjiNumeralWords are the words of a numeral and a counter which are not a
+// number, such as 十八番 (one's specialty).
+var kanjiNumeralWords = map[string]bool{
+	"十八番": true,
+}
+
+// KanjiNumeralToArabicNumeral converts kanji numerals to hankaku Arabic
+// numerals, such as 三 to 3, 二〇二三 to 2023 and 二千二十三 to 2023. Arabic
+// digits among kanji numerals are read as well, such as 1万5千 to 15000.
+// Numerals joined to other kanji are a part of a word and are passed through,
+// such as 六本木, 八王子, 一緒, 統一 and 唯一人, except before a counter or a unit
+// not followed by other kanji, such as 三人 and 二十円, or followed by a suffix,
+// such as 六丁目 of 六本木六丁目, and after 第. A unit alone is passed through
+// too, such as 千 of 千葉 and 十 of 十分.
+func KanjiNumeralToArabicNumeral(in <-chan KanaConverterRune) <-chan KanaConverterRune {
+	return convertForKanaConverter(&kanjiNumeralToArabicNumeralStage{}, in)
+}
+
+// kanjiNumeralToArabicNumeralStage holds a run of numerals until it ends, and
+// then a counter after it until the rune following the counter.
+type kanjiNumeralToArabicNumeralStage struct {
+	run []KanaConverterRune
+	// before is the rune before the run
+	before rune
+	// counter is the counter held after the run
+	counter *KanaConverterRune
+}
+
+func isKanjiNumeral(r rune) bool {
+	_, digit := kanjiNumeralDigits[r]
+	_, small := kanjiNumeralSmallUnits[r]
+	_, large := kanjiNumeralLargeUnits[r]
+	return digit || small || large
+}
+
+// isKanjiNumeralJoined reports whether a numeral followed by r is a part of a
+// word.
+func isKanjiNumeralJoined(r rune) bool {
+	return unicode.Is(unicode.Han, r) && !isKanjiNumeral(r)
+}
+
+// isKanjiNumeralJoinedAfter reports whether a numeral after r is a part of a
+// word. r is not a numeral but 万, 億 or 兆 starting a word, such as 万 of 万一.
+func isKanjiNumeralJoinedAfter(r rune) bool {
+	return unicode.Is(unicode.Han, r) && r != '第'
+}
+
+func (s *kanjiNumeralToArabicNumeralStage) Push(dst []KanaConverterRune, r KanaConverterRune) []KanaConverterRune {
+	if s.counter != nil {
+		// a counter between other kanji is a part of a word, such as 本 of
+		// 六本木 and 人 of 唯一人, unless a suffix follows it, such as 目 of
+		// 六本木六丁目
+		convert := strings.ContainsRune(kanjiNumeralSuffixes, r.Rune) ||
+			(!isKanjiNumeralJoined(r.Rune) && !isKanjiNumeralJoinedAfter(s.before))
+		convert = convert && !s.isWord()
+		dst = append(s.resolve(dst, convert), *s.counter)
+		// a numeral after a counter converted starts a number, such as 一日 of
+		// 一月一日
+		s.before = s.counter.Rune
+		if convert {
+			s.before = 0
+		}
+		s.counter = nil
+	}
+
+	_, arabic := arabicNumeralDigit(r.Rune)
+	_, large := kanjiNumeralLargeUnits[r.Rune]
+	switch {
+	case large && len(s.run) == 0:
+		// a numeral does not start with 万, 億 or 兆, such as 万 of 万一
+	case arabic || isKanjiNumeral(r.Rune):
+		s.run = append(s.run, r)
+		return dst
+	case len(s.run) > 0 && strings.ContainsRune(kanjiNumeralCounters, r.Rune):
+		s.counter = &r
+		return dst
+	case len(s.run) > 0:
+		dst = s.resolve(dst, !isKanjiNumeralJoined(r.Rune) && !isKanjiNumeralJoinedAfter(s.before))
+	}
+	s.before = r.Rune
+	return append(dst, r)
+}
+
+func (s *kanjiNumeralToArabicNumeralStage) Flush(dst []KanaConverterRune) []KanaConverterRune {
+	if s.counter != nil {
+		dst = append(s.resolve(dst, !isKanjiNumeralJoinedAfter(s.before) && !s.isWord()), *s.counter)
+	} else if len(s.run) > 0 {
+		dst = s.resolve(dst, !isKanjiNumeralJoinedAfter(s.before))
+	}
+	s.before, s.counter = 0, nil
+	return dst
+}
+
+// isWord reports whether the run and the counter held are one of
+// kanjiNumeralWords.
+func (s *kanjiNumeralToArabicNumeralStage) isWord() bool {
+	var b strings.Builder
+	for _, r := range s.run {
+		b.WriteRune(r.Rune)
+	}
+	b.WriteRune(s.counter.Rune)
+	return kanjiNumeralWords[b.String()]
+}
+
+// resolve writes the run held, converted if convert.
+func (s *kanjiNumeralToArabicNumeralStage) resolve(dst []KanaConverterRune, convert bool) []KanaConverterRune {
+	run := s.run
+	s.run = s.run[:0]
+	if !convert {
+		return append(dst, run...)
+	}
+
+	kanji := false
+	for _, r := range run {
+		if isKanjiNumeral(r.Rune) {
+			kanji = true
+			break
+		}
+	}
+	_, digit := kanjiNumeralDigits[run[0].Rune]
+	if len(run) == 1 && !digit {
+		// a unit alone is rather a part of a word, such as 千 of 千葉
+		return append(dst, run...)
+	}
+	n, ok := parseKanjiNumeral(run)
+	if !kanji || !ok {
+		return append(dst, run...)
+	}
+	return appendKanaConverterSpan(dst, run, n)
+}
+
+// parseKanjiNumeral reads run either as digits or as a number with units. It
+// reports false for a run which is not a number, such as 十百.
+func parseKanjiNumeral(run []KanaConverterRune) (string, bool) {
+	units := false
+	for _, r := range run {
+		_, small := kanjiNumeralSmallUnits[r.Rune]
+		_, large := kanjiNumeralLargeUnits[r.Rune]
+		units = units || small || large
+	}
+	if !units {
+		var b strings.Builder
+		for _, r := range run {
+			d, ok := kanjiNumeralDigits[r.Rune]
+			if !ok {
+				d, _ = arabicNumeralDigit(r.Rune)
+			}
+			b.WriteByte(byte('0' + d))
+		}
+		return b.String(), true
+	}
+
+	var total, section, current uint64
+	hasCurrent := false
+	lastSmall, lastLarge := uint64(kanjiNumeralLimit), uint64(kanjiNumeralLimit)
+	for _, r := range run {
+		if d, ok := kanjiNumeralDigits[r.Rune]; ok {
+			current, hasCurrent = current*10+d, true
+		} else if d, ok := arabicNumeralDigit(r.Rune); ok {
+			current, hasCurrent = current*10+d, true
+		} else if u, ok := kanjiNumeralSmallUnits[r.Rune]; ok {
+			if u >= lastSmall {
+				return "", false
+			}
+			if !hasCurrent {
+				current = 1
+			}
+			section += current * u
+			current, hasCurrent, lastSmall = 0, false, u
+		} else if u, ok := kanjiNumeralLargeUnits[r.Rune]; ok {
+			if u >= lastLarge {
+				return "", false
+			}
+			section += current
+			if section == 0 {
+				section = 1
+			}
+			total += section * u
+			section, current, hasCurrent, lastSmall, lastLarge = 0, 0, false, kanjiNumeralLimit, u
+		}
+		if current >= kanjiNumeralLimit || section >= kanjiNumeralLimit || total >= kanjiNumeralLimit {
+			return "", false
+		}
+	}
+	return formatUint(total + section + current), true
+}
+
+// ArabicNumeralToKanjiNumeral converts hankaku and zenkaku Arabic numerals to
+// kanji numerals. With positional, a number is written with units, such as
+// 2023 to 二千二十三, otherwise digit by digit, such as 2023 to 二〇二三.
+// Positional numbers separated by commas every three digits are read as one,
+// such as 1,000 to 千. Numbers starting with 0 and numbers of 10^16 or more
+// are written digit by digit, and so are decimal fractions, such as 3.14 to
+// 三.一四.
+func ArabicNumeralToKanjiNumeral(in <-chan KanaConverterRune, positional bool) <-chan KanaConverterRune {
+	return convertForKanaConverter(&arabicNumeralToKanjiNumeralStage{positional: positional}, in)
+}
+
+// arabicNumeralToKanjiNumeralStage holds a run of digits, commas and a
+// decimal point until it ends.
+type arabicNumeralToKanjiNumeralStage struct {
+	positional bool
+	run        []KanaConverterRune
+	// point is whether the run has a decimal point
+	point bool
+}
+
+func isArabicNumeralSeparator(r rune) bool {
+	return r == ',' || r == '，'
+}
+
+func isArabicNumeralPoint(r rune) bool {
+	return r == '.' || r == '．'
+}
+
+func (s *arabicNumeralToKanjiNumeralStage) Push(dst []KanaConverterRune, r KanaConverterRune) []KanaConverterRune {
+	if _, ok := arabicNumeralDigit(r.Rune); ok {
+		s.run = append(s.run, r)
+		return dst
+	}
+	if isArabicNumeralSeparator(r.Rune) && len(s.run) > 0 && !s.point {
+		s.run = append(s.run, r)
+		return dst
+	}
+	if isArabicNumeralPoint(r.Rune) && len(s.run) > 0 && !s.point {
+		if _, ok := arabicNumeralDigit(s.run[len(s.run)-1].Rune); ok {
+			s.run = append(s.run, r)
+			s.point = true
+			return dst
+		}
+	}
+	return append(s.Flush(dst), r)
+}
+
+func (s *arabicNumeralToKanjiNumeralStage) Flush(dst []KanaConverterRune) []KanaConverterRune {
+	if len(s.run) == 0 {
+		return dst
+	}
+	run := s.run
+	s.run = s.run[:0]
+	s.point = false
+
+	// a trailing separator or point is not a part of the number
+	var trailing []KanaConverterRune
+	if last := len(run) - 1; isArabicNumeralSeparator(run[last].Rune) || isArabicNumeralPoint(run[last].Rune) {
+		run, trailing = run[:last], run[last:]
+	}
+	var fraction []KanaConverterRune
+	for i, r := range run {
+		if isArabicNumeralPoint(r.Rune) {
+			run, fraction = run[:i], run[i:]
+			break
+		}
+	}
+	if s.positional && isArabicNumeralGrouped(run) {
+		dst = appendKanjiNumeral(dst, run, true)
+	} else {
+		start := 0
+		for i, r := range run {
+			if isArabicNumeralSeparator(r.Rune) {
+				dst = appendKanjiNumeral(dst, run[start:i], s.positional)
+				dst = append(dst, r)
+				start = i + 1
+			}
+		}
+		dst = appendKanjiNumeral(dst, run[start:], s.positional)
+	}
+	if len(fraction) > 0 {
+		dst = append(dst, fraction[0])
+		dst = appendKanjiNumeral(dst, fraction[1:], false)
+	}
+	return append(dst, trailing...)
+}
+
+// isArabicNumeralGrouped reports whether the separators of run are every
+// three digits, such as 1,000, or there are none.
+func isArabicNumeralGrouped(run []KanaConverterRune) bool {
+	group := 0
+	separated := false
+	for _, r := range run {
+		if !isArabicNumeralSeparator(r.Rune) {
+			group++
+			continue
+		}
+		if group == 0 || (separated && group != 3) || (!separated && group > 3) {
+			return false
+		}
+		group, separated = 0, true
+	}
+	return !separated || group == 3
+}
+
+var kanjiNumeralDigitRunes = []rune("〇一二三四五六七八九")
+
+// appendKanjiNumeral appends the kanji numeral of the digits of run, skipping
+// separators.
+func appendKanjiNumeral(dst []KanaConverterRune, run []KanaConverterRune, positional bool) []KanaConverterRune {
+	var digits []uint64
+	for _, r := range run {
+		if d, ok := arabicNumeralDigit(r.Rune); ok {
+			digits = append(digits, d)
+		}
+	}
+	if len(digits) == 0 {
+		return dst
+	}
+	var n uint64
+	if positional && (digits[0] != 0 || len(digits) == 1) && len(digits) <= 16 {
+		for _, d := range digits {
+			n = n*10 + d
+		}
+		return appendKanaConverterSpan(dst, run, formatKanjiNumeral(n))
+	}
+	var b strings.Builder
+	for _, d := range digits {
+		b.WriteRune(kanjiNumeralDigitRunes[d])
+	}
+	return appendKanaConverterSpan(dst, run, b.String())
+}
+
+// formatKanjiNumeral writes n with units, omitting 一 before 十, 百 and 千.
+func formatKanjiNumeral(n uint64) string {
+	if n == 0 {
+		return "〇"
+	}
+	var b strings.Builder
+	for _, large := range []struct {
+		unit uint64
+		name string
+	}{{1_0000_0000_0000, "兆"}, {1_0000_0000, "億"}, {1_0000, "万"}, {1, ""}} {
+		section := n / large.unit % 1_0000
+		if section == 0 {
+			continue
+		}
+		for _, small := range []struct {
+			unit uint64
+			name string
+		}{{1000, "千"}, {100, "百"}, {10, "十"}, {1, ""}} {
+			d := section / small.unit % 10
+			if d == 0 {
+				continue
+			}
+			if d != 1 || small.unit == 1 {
+				b.WriteRune(kanjiNumeralDigitRunes[d])
+			}
+			b.WriteString(small.name)
+		}
+		b.WriteString(large.name)
+	}
+	return b.String()
+}
+
+// appendKanaConverterSpan appends the runes of s converted from run, each
+// covering the whole run in the input.
+func appendKanaConverterSpan(dst []KanaConverterRune, run []KanaConverterRune, s string) []KanaConverterRune {
+	span := KanaConverterRune{Start: run[0].Start, End: run[len(run)-1].End}
+	for _, c := range s {
+		dst = append(dst, span.convert(c))
+	}
+	return dst
+}
+
+func formatUint(n uint64) string {
+	var buf [20]byte
+	i := len(buf)
+	for {
+		i--
+		buf[i] = byte('0' + n%10)
+		n /= 10
+		if n == 0 {
+			break
+		}
+	}
+	return string(buf[i:])
+}
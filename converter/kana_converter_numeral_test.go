@@ -0,0 +1,218 @@
+package converter_test
+
+import (
+	"testing"
+
+	"github.com/ArmadaSuit/udf-go/converter"
+)
+
+func TestKanjiNumeralToArabicNumeral(t *testing.T) {
+	type args struct {
+		in   string
+		mode string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "digits",
+			args: args{in: "三丁目、二〇二三年、零", mode: "j"},
+			want: "3丁目、2023年、0",
+		},
+		{
+			name: "positional",
+			args: args{in: "二千二十三年、十一月、三百円", mode: "j"},
+			want: "2023年、11月、300円",
+		},
+		{
+			name: "large units",
+			args: args{in: "三千五百万、一億二千万三、九千九百九十九兆九千九百九十九億九千九百九十九万九千九百九十九", mode: "j"},
+			want: "35000000、120000003、9999999999999999",
+		},
+		{
+			name: "zero among units",
+			args: args{in: "千〇一", mode: "j"},
+			want: "1001",
+		},
+		{
+			name: "arabic digits among kanji numerals",
+			args: args{in: "1万5千円、３億", mode: "j"},
+			want: "15000円、300000000",
+		},
+		{
+			name: "arabic digits alone are kept",
+			args: args{in: "3丁目、３丁目", mode: "j"},
+			want: "3丁目、３丁目",
+		},
+		{
+			name: "units without a digit",
+			args: args{in: "十五日、百万円", mode: "j"},
+			want: "15日、1000000円",
+		},
+		{
+			name: "a unit alone is kept",
+			args: args{in: "千葉、十分、十月", mode: "j"},
+			want: "千葉、十分、十月",
+		},
+		{
+			name: "units out of order are kept",
+			args: args{in: "十百、千万億", mode: "j"},
+			want: "十百、千万億",
+		},
+		{
+			name: "numerals do not start with a large unit",
+			args: args{in: "万一、万 一", mode: "j"},
+			want: "万一、万 1",
+		},
+		{
+			name: "numerals joined to kanji are kept",
+			args: args{in: "六本木、八王子、一緒、統一、十分、九十九里、五十嵐、三日月", mode: "j"},
+			want: "六本木、八王子、一緒、統一、十分、九十九里、五十嵐、三日月",
+		},
+		{
+			name: "numerals joined to kanji before a counter",
+			args: args{in: "六本木六丁目、三番、一月一日、三人以上、第一", mode: "j"},
+			want: "六本木6丁目、3番、1月1日、3人以上、第1",
+		},
+		{
+			name: "numerals and counters in words are kept",
+			args: args{in: "関東一円、唯一人、八戸、十八番、関東一円に、唯一人で、十八番を", mode: "j"},
+			want: "関東一円、唯一人、八戸、十八番、関東一円に、唯一人で、十八番を",
+		},
+		{
+			name: "units out of order are kept",
+			args: args{in: "一十百、一万一億", mode: "j"},
+			want: "一十百、一万一億",
+		},
+		{
+			name: "after zenkaku number to hankaku number",
+			args: args{in: "１万", mode: "nj"},
+			want: "10000",
+		},
+		{
+			name:    "positional to plain",
+			args:    args{in: "二千二十三", mode: "jD"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+
+			t.Parallel()
+
+			c, err := converter.Compile(tt.args.mode)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Compile() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+			if got := c.Convert(tt.args.in); got != tt.want {
+				t.Errorf("%v is converted %v, want %v", tt.args.in, got, tt.want)
+			}
+			if got, _ := convertByChannel(tt.args.in, tt.args.mode); got != tt.want {
+				t.Errorf("%v is converted %v by channels, want %v", tt.args.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestArabicNumeralToKanjiNumeral(t *testing.T) {
+	type args struct {
+		in   string
+		mode string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "positional",
+			args: args{in: "2023年11月10日、3丁目", mode: "J"},
+			want: "二千二十三年十一月十日、三丁目",
+		},
+		{
+			name: "positional large units",
+			args: args{in: "0、100、1000、10000、120000003、9999999999999999", mode: "J"},
+			want: "〇、百、千、一万、一億二千万三、九千九百九十九兆九千九百九十九億九千九百九十九万九千九百九十九",
+		},
+		{
+			name: "positional zenkaku",
+			args: args{in: "２０２３", mode: "J"},
+			want: "二千二十三",
+		},
+		{
+			name: "positional grouped by commas",
+			args: args{in: "1,000円、12,345,678円、1,00円、1,000,", mode: "J"},
+			want: "千円、千二百三十四万五千六百七十八円、一,〇〇円、千,",
+		},
+		{
+			name: "positional leading zero and too large",
+			args: args{in: "007、10000000000000000", mode: "J"},
+			want: "〇〇七、一〇〇〇〇〇〇〇〇〇〇〇〇〇〇〇〇",
+		},
+		{
+			name: "positional decimal fraction",
+			args: args{in: "3.14、1,000.05、０．５、3.、v1.2.3", mode: "J"},
+			want: "三.一四、千.〇五、〇．五、三.、v一.二.三",
+		},
+		{
+			name: "plain",
+			args: args{in: "2023年、１,000", mode: "D"},
+			want: "二〇二三年、一,〇〇〇",
+		},
+		{
+			name: "plain decimal fraction",
+			args: args{in: "3.14", mode: "D"},
+			want: "三.一四",
+		},
+		{
+			name:    "positional and plain",
+			args:    args{mode: "JD"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+
+			t.Parallel()
+
+			c, err := converter.Compile(tt.args.mode)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Compile() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+			if got := c.Convert(tt.args.in); got != tt.want {
+				t.Errorf("%v is converted %v, want %v", tt.args.in, got, tt.want)
+			}
+			if got, _ := convertByChannel(tt.args.in, tt.args.mode); got != tt.want {
+				t.Errorf("%v is converted %v by channels, want %v", tt.args.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKanjiNumeralOffsets(t *testing.T) {
+	c, err := converter.Compile("j")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, offsets := c.ConvertWithOffsets("第二十三")
+	want := converter.KanaConverterSpan{Start: 3, End: 12}
+	for _, o := range offsets[1:] {
+		if got := o.Input; got != want {
+			t.Errorf("input of %v = %v, want %v", o, got, want)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package converter
+
+import (
+	"sort"
+	"strings"
+)
+
+// KanaConverterSpan is the range [Start, End) of a string.
+type KanaConverterSpan struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// KanaConverterOffset maps a rune of a converted string to the part of the
+// input it is converted from. Runes merged by a conversion (ｶﾞ -> ガ) share
+// one output rune and runes split by a conversion (ガ -> ｶﾞ) share one input
+// span.
+type KanaConverterOffset struct {
+	// Output and Input are byte offsets.
+	Output KanaConverterSpan
+	Input  KanaConverterSpan
+	// OutputRune and InputRunes are rune indexes.
+	OutputRune int
+	InputRunes KanaConverterSpan
+}
+
+// KanaConverterOffsets holds a KanaConverterOffset for each rune of a
+// converted string in order.
+type KanaConverterOffsets []KanaConverterOffset
+
+// ConvertWithOffsets returns s converted by the compiled mode together with
+// the offsets of every output rune in s.
+func (c *KanaConverter) ConvertWithOffsets(s string) (string, KanaConverterOffsets) {
+	st := c.pool.Get().(*kanaConverterState)
+	defer c.pool.Put(st)
+
+	in := appendKanaConverterRunes(st.src[:0], s)
+	starts := make([]int, 0, len(in))
+	for _, r := range in {
+		starts = append(starts, r.Start)
+	}
+	out := st.run(in)
+
+	var b strings.Builder
+	b.Grow(len(s))
+	offsets := make(KanaConverterOffsets, 0, len(out))
+	for i, r := range out {
+		start := b.Len()
+		b.WriteRune(r.Rune)
+		offsets = append(offsets, KanaConverterOffset{
+			Output:     KanaConverterSpan{Start: start, End: b.Len()},
+			Input:      KanaConverterSpan{Start: r.Start, End: r.End},
+			OutputRune: i,
+			InputRunes: KanaConverterSpan{Start: sort.SearchInts(starts, r.Start), End: sort.SearchInts(starts, r.End)},
+		})
+	}
+	return b.String(), offsets
+}
+
+// Input returns the bytes of the input that the output bytes [start, end) are
+// converted from. It reports false if no output rune overlaps the range.
+func (o KanaConverterOffsets) Input(start, end int) (KanaConverterSpan, bool) {
+	i := sort.Search(len(o), func(i int) bool {
+		return o[i].Output.End > start
+	})
+	return o.input(i, end, func(offset KanaConverterOffset) (KanaConverterSpan, int) {
+		return offset.Input, offset.Output.Start
+	})
+}
+
+// InputRunes is Input for rune indexes.
+func (o KanaConverterOffsets) InputRunes(start, end int) (KanaConverterSpan, bool) {
+	i := sort.Search(len(o), func(i int) bool {
+		return o[i].OutputRune >= start
+	})
+	return o.input(i, end, func(offset KanaConverterOffset) (KanaConverterSpan, int) {
+		return offset.InputRunes, offset.OutputRune
+	})
+}
+
+// input merges the input spans of the offsets from i while their output
+// starts before end.
+func (o KanaConverterOffsets) input(i, end int, get func(KanaConverterOffset) (KanaConverterSpan, int)) (KanaConverterSpan, bool) {
+	var span KanaConverterSpan
+	found := false
+	for ; i < len(o); i++ {
+		in, outStart := get(o[i])
+		if outStart >= end {
+			break
+		}
+		if !found || in.Start < span.Start {
+			span.Start = in.Start
+		}
+		if !found || in.End > span.End {
+			span.End = in.End
+		}
+		found = true
+	}
+	return span, found
+}
@@ -0,0 +1,148 @@
+package converter_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ArmadaSuit/udf-go/converter"
+)
+
+func TestKanaConverterConvertWithOffsets(t *testing.T) {
+	type args struct {
+		in   string
+		mode string
+	}
+	type span = converter.KanaConverterSpan
+	tests := []struct {
+		name        string
+		args        args
+		want        string
+		wantOffsets converter.KanaConverterOffsets
+	}{
+		{
+			name: "not converted",
+			args: args{in: "aあ", mode: "KV"},
+			want: "aあ",
+			wantOffsets: converter.KanaConverterOffsets{
+				{Output: span{Start: 0, End: 1}, Input: span{Start: 0, End: 1}, OutputRune: 0, InputRunes: span{Start: 0, End: 1}},
+				{Output: span{Start: 1, End: 4}, Input: span{Start: 1, End: 4}, OutputRune: 1, InputRunes: span{Start: 1, End: 2}},
+			},
+		},
+		{
+			name: "hankaku katakana and voiced mark are merged",
+			args: args{in: "ｶﾞｷ", mode: "KV"},
+			want: "ガキ",
+			wantOffsets: converter.KanaConverterOffsets{
+				{Output: span{Start: 0, End: 3}, Input: span{Start: 0, End: 6}, OutputRune: 0, InputRunes: span{Start: 0, End: 2}},
+				{Output: span{Start: 3, End: 6}, Input: span{Start: 6, End: 9}, OutputRune: 1, InputRunes: span{Start: 2, End: 3}},
+			},
+		},
+		{
+			name: "zenkaku katakana is split into hankaku katakana and voiced mark",
+			args: args{in: "ガa", mode: "kR"},
+			want: "ｶﾞａ",
+			wantOffsets: converter.KanaConverterOffsets{
+				{Output: span{Start: 0, End: 3}, Input: span{Start: 0, End: 3}, OutputRune: 0, InputRunes: span{Start: 0, End: 1}},
+				{Output: span{Start: 3, End: 6}, Input: span{Start: 0, End: 3}, OutputRune: 1, InputRunes: span{Start: 0, End: 1}},
+				{Output: span{Start: 6, End: 9}, Input: span{Start: 3, End: 4}, OutputRune: 2, InputRunes: span{Start: 1, End: 2}},
+			},
+		},
+		{
+			name: "hankaku katakana and semi-voiced mark which are not merged",
+			args: args{in: "ｶﾟ", mode: "HV"},
+			want: "か゜",
+			wantOffsets: converter.KanaConverterOffsets{
+				{Output: span{Start: 0, End: 3}, Input: span{Start: 0, End: 3}, OutputRune: 0, InputRunes: span{Start: 0, End: 1}},
+				{Output: span{Start: 3, End: 6}, Input: span{Start: 3, End: 6}, OutputRune: 1, InputRunes: span{Start: 1, End: 2}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+
+			t.Parallel()
+
+			c, err := converter.Compile(tt.args.mode)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, gotOffsets := c.ConvertWithOffsets(tt.args.in)
+			if got != tt.want {
+				t.Errorf("%v is converted %v, want %v", tt.args.in, got, tt.want)
+			}
+			if !reflect.DeepEqual(gotOffsets, tt.wantOffsets) {
+				t.Errorf("offsets of %v = %v, want %v", tt.args.in, gotOffsets, tt.wantOffsets)
+			}
+		})
+	}
+}
+
+func TestKanaConverterOffsetsInput(t *testing.T) {
+	c, err := converter.Compile("KVa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// "ﾎﾞｰﾙﾍﾟﾝ　ＡＢＣ" -> "ボールペン　ABC"
+	in := "ﾎﾞｰﾙﾍﾟﾝ　ＡＢＣ"
+	out, offsets := c.ConvertWithOffsets(in)
+	if out != "ボールペン　ABC" {
+		t.Fatalf("%v is converted %v", in, out)
+	}
+
+	type want struct {
+		span converter.KanaConverterSpan
+		ok   bool
+	}
+	tests := []struct {
+		name      string
+		start     int
+		end       int
+		runeStart int
+		runeEnd   int
+		want      want
+		wantRunes want
+	}{
+		{
+			name:      "merged rune",
+			start:     0,
+			end:       3,
+			runeStart: 0,
+			runeEnd:   1,
+			want:      want{span: converter.KanaConverterSpan{Start: 0, End: 6}, ok: true},
+			wantRunes: want{span: converter.KanaConverterSpan{Start: 0, End: 2}, ok: true},
+		},
+		{
+			name:      "word",
+			start:     9,
+			end:       15,
+			runeStart: 3,
+			runeEnd:   5,
+			want:      want{span: converter.KanaConverterSpan{Start: 12, End: 21}, ok: true},
+			wantRunes: want{span: converter.KanaConverterSpan{Start: 4, End: 7}, ok: true},
+		},
+		{
+			name:      "out of range",
+			start:     100,
+			end:       200,
+			runeStart: 100,
+			runeEnd:   200,
+			want:      want{},
+			wantRunes: want{},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+
+			t.Parallel()
+
+			if got, ok := offsets.Input(tt.start, tt.end); got != tt.want.span || ok != tt.want.ok {
+				t.Errorf("Input(%v, %v) = %v, %v, want %v, %v", tt.start, tt.end, got, ok, tt.want.span, tt.want.ok)
+			}
+			if got, ok := offsets.InputRunes(tt.runeStart, tt.runeEnd); got != tt.wantRunes.span || ok != tt.wantRunes.ok {
+				t.Errorf("InputRunes(%v, %v) = %v, %v, want %v, %v", tt.runeStart, tt.runeEnd, got, ok, tt.wantRunes.span, tt.wantRunes.ok)
+			}
+		})
+	}
+}
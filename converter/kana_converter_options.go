@@ -2,6 +2,7 @@ package converter
 
 import (
 	"fmt"
+	"strings"
 )
 
 type KanaConverterOptions struct {
@@ -20,6 +21,17 @@ type KanaConverterOptions struct {
 	optc bool
 	optC bool
 	optV bool
+	optM bool
+	optm bool
+	optj bool
+	optJ bool
+	optD bool
+
+	// flags, stages and conflicts are of the registered stages and mappings
+	// enabled, in the order of the mode
+	flags     []string
+	stages    []kanaConverterStageBuilder
+	conflicts []kanaConverterConflict
 }
 
 func (r *KanaConverterOptions) EnableOptr() error {
@@ -199,9 +211,67 @@ func (r *KanaConverterOptions) EnableOptV() error {
 	return nil
 }
 
+func (r *KanaConverterOptions) EnableOptM() error {
+	if r.optm {
+		return fmt.Errorf("must not combine 'M' and 'm' flags")
+	}
+
+	r.optM = true
+	return nil
+}
+
+func (r *KanaConverterOptions) EnableOptm() error {
+	if r.optM {
+		return fmt.Errorf("must not combine 'M' and 'm' flags")
+	}
+
+	r.optm = true
+	return nil
+}
+
+func (r *KanaConverterOptions) EnableOptj() error {
+	if r.optJ {
+		return fmt.Errorf("must not combine 'j' and 'J' flags")
+	}
+	if r.optD {
+		return fmt.Errorf("must not combine 'j' and 'D' flags")
+	}
+
+	r.optj = true
+	return nil
+}
+
+func (r *KanaConverterOptions) EnableOptJ() error {
+	if r.optj {
+		return fmt.Errorf("must not combine 'j' and 'J' flags")
+	}
+	if r.optD {
+		return fmt.Errorf("must not combine 'J' and 'D' flags")
+	}
+
+	r.optJ = true
+	return nil
+}
+
+func (r *KanaConverterOptions) EnableOptD() error {
+	if r.optj {
+		return fmt.Errorf("must not combine 'j' and 'D' flags")
+	}
+	if r.optJ {
+		return fmt.Errorf("must not combine 'J' and 'D' flags")
+	}
+
+	r.optD = true
+	return nil
+}
+
+// NewKanaConverterOptions parses mode, which is option letters optionally
+// followed by names of registered stages or mappings each prefixed with '+'.
+// An unknown letter or name is an error.
 func NewKanaConverterOptions(mode string) (*KanaConverterOptions, error) {
 	o := &KanaConverterOptions{}
-	for _, char := range mode {
+	names := strings.Split(mode, "+")
+	for _, char := range names[0] {
 		var err error
 		switch char {
 		case rune('r'):
@@ -234,11 +304,35 @@ func NewKanaConverterOptions(mode string) (*KanaConverterOptions, error) {
 			err = o.EnableOptC()
 		case rune('V'):
 			err = o.EnableOptV()
+		case rune('M'):
+			err = o.EnableOptM()
+		case rune('m'):
+			err = o.EnableOptm()
+		case rune('j'):
+			err = o.EnableOptj()
+		case rune('J'):
+			err = o.EnableOptJ()
+		case rune('D'):
+			err = o.EnableOptD()
+		default:
+			d := lookupKanaConverterStageByLetter(char)
+			if d == nil {
+				return nil, fmt.Errorf("unknown '%c' flag", char)
+			}
+			o.enableStage(d, string(char))
 		}
 		if err != nil {
 			return nil, err
 		}
 	}
+	for _, name := range names[1:] {
+		if err := o.enableName(name); err != nil {
+			return nil, err
+		}
+	}
+	if err := o.checkConflicts(); err != nil {
+		return nil, err
+	}
 
 	return o, nil
 }
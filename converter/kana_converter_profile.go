@@ -0,0 +1,62 @@
+package converter
+
+import (
+	"unicode"
+)
+
+// KanaProfile is the number of the characters of a text in each category,
+// telling which conversions the text needs. Each character is counted in one
+// category.
+type KanaProfile struct {
+	// HankakuKatakana is the characters of KanaClassHankakuKatakana.
+	HankakuKatakana int `json:"hankaku_katakana"`
+	// ZenkakuKatakana is the characters of KanaClassZenkakuKatakana, and ー.
+	ZenkakuKatakana int `json:"zenkaku_katakana"`
+	// Hiragana is the characters of KanaClassHiragana except ー.
+	Hiragana int `json:"hiragana"`
+	// Kanji is the Han characters, including 々 and 〆.
+	Kanji int `json:"kanji"`
+	// ZenkakuLatin is the zenkaku forms of ASCII, such as Ａ, ａ, ０ and ！.
+	ZenkakuLatin int `json:"zenkaku_latin"`
+	// ASCII is U+0000 to U+007F.
+	ASCII int `json:"ascii"`
+	// Symbols is the other punctuation, symbols and spaces, such as 、, 「 and
+	// U+3000.
+	Symbols int `json:"symbols"`
+	// Others is the rest, including invalid UTF-8 bytes.
+	Others int `json:"others"`
+	// Characters is the number of all the characters.
+	Characters int `json:"characters"`
+	// Width is the display width by StringWidth.
+	Width int `json:"width"`
+}
+
+// ProfileKana counts the characters of s in each category.
+func ProfileKana(s string) KanaProfile {
+	var p KanaProfile
+	for i, r := range s {
+		p.Characters++
+		switch {
+		case isInvalidUTF8At(s, i, r):
+			p.Others++
+		case r <= unicode.MaxASCII:
+			p.ASCII++
+		case KanaClassHankakuKatakana.contains(r):
+			p.HankakuKatakana++
+		case KanaClassZenkakuKatakana.contains(r):
+			p.ZenkakuKatakana++
+		case KanaClassHiragana.contains(r):
+			p.Hiragana++
+		case unicode.Is(unicode.Han, r) || r == '〆':
+			p.Kanji++
+		case r >= '！' && r <= '～':
+			p.ZenkakuLatin++
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			p.Symbols++
+		default:
+			p.Others++
+		}
+		p.Width += RuneWidth(r)
+	}
+	return p
+}
@@ -0,0 +1,55 @@
+package converter_test
+
+import (
+	"testing"
+
+	"github.com/ArmadaSuit/udf-go/converter"
+)
+
+func TestProfileKana(t *testing.T) {
+	type args struct {
+		s string
+	}
+	tests := []struct {
+		name string
+		args args
+		want converter.KanaProfile
+	}{
+		{
+			name: "empty",
+			args: args{s: ""},
+			want: converter.KanaProfile{},
+		},
+		{
+			name: "kana",
+			args: args{s: "ｶﾞｯｺｳ がっこう ガッコー"},
+			want: converter.KanaProfile{HankakuKatakana: 5, Hiragana: 4, ZenkakuKatakana: 4, ASCII: 2, Characters: 15, Width: 23},
+		},
+		{
+			name: "kanji and latin",
+			args: args{s: "山田々〆Ａｂ１！abc"},
+			want: converter.KanaProfile{Kanji: 4, ZenkakuLatin: 4, ASCII: 3, Characters: 11, Width: 19},
+		},
+		{
+			name: "symbols and others",
+			args: args{s: "「、。」　★한\u3099"},
+			want: converter.KanaProfile{Symbols: 6, Others: 2, Characters: 8, Width: 15},
+		},
+		{
+			name: "invalid utf-8",
+			args: args{s: "a\xff�"},
+			want: converter.KanaProfile{ASCII: 1, Others: 1, Symbols: 1, Characters: 3, Width: 3},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+
+			t.Parallel()
+
+			if got := converter.ProfileKana(tt.args.s); got != tt.want {
+				t.Errorf("%v is profiled %+v, want %+v", tt.args.s, got, tt.want)
+			}
+		})
+	}
+}
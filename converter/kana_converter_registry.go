@@ -0,0 +1,182 @@
+package converter
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// KanaConverterStageDefinition describes a stage added to modes by
+// RegisterKanaConverterStage.
+type KanaConverterStageDefinition struct {
+	// Name enables the stage in a mode after the options prefixed with '+',
+	// such as "KV+name". It is also the name reported by KanaConverter.Explain.
+	Name string
+	// Letter enables the stage as an option letter. It is optional.
+	Letter rune
+	// Conflicts lists the letters and names which must not be combined with the
+	// stage, built-in or registered.
+	Conflicts []string
+	// NewStage returns a new stage. The stage is reused for the following
+	// conversions, so its Flush must reset all the state kept between runes.
+	NewStage func() KanaConverterStage
+}
+
+var kanaConverterStages = struct {
+	sync.RWMutex
+	byName   map[string]*KanaConverterStageDefinition
+	byLetter map[rune]*KanaConverterStageDefinition
+}{
+	byName:   make(map[string]*KanaConverterStageDefinition),
+	byLetter: make(map[rune]*KanaConverterStageDefinition),
+}
+
+// kanaConverterBuiltinLetters are the letters of mb_convert_kana followed by
+// the ones of this package.
+const kanaConverterBuiltinLetters = "rRnNaAsSkKhHcCVMmjJD"
+
+// RegisterKanaConverterStage makes a stage available to modes by its letter
+// and name. Registered stages run after the built-in ones in the order they
+// are given in the mode, together with mappings. A name is looked up in the
+// registered stages before the mappings.
+func RegisterKanaConverterStage(d KanaConverterStageDefinition) error {
+	if d.Name == "" {
+		return fmt.Errorf("stage name must not be empty")
+	}
+	if strings.Contains(d.Name, "+") {
+		return fmt.Errorf("stage name %q must not contain '+'", d.Name)
+	}
+	if d.NewStage == nil {
+		return fmt.Errorf("stage %q has no NewStage", d.Name)
+	}
+	if d.Letter == '+' || strings.ContainsRune(kanaConverterBuiltinLetters, d.Letter) {
+		return fmt.Errorf("stage %q must not use the letter '%c'", d.Name, d.Letter)
+	}
+
+	kanaConverterStages.Lock()
+	defer kanaConverterStages.Unlock()
+	if _, ok := kanaConverterStages.byName[d.Name]; ok {
+		return fmt.Errorf("stage %q is already registered", d.Name)
+	}
+	if _, ok := kanaConverterStages.byLetter[d.Letter]; ok && d.Letter != 0 {
+		return fmt.Errorf("stage %q must not use the letter '%c' already registered", d.Name, d.Letter)
+	}
+	d.Conflicts = append([]string(nil), d.Conflicts...)
+	kanaConverterStages.byName[d.Name] = &d
+	if d.Letter != 0 {
+		kanaConverterStages.byLetter[d.Letter] = &d
+	}
+	return nil
+}
+
+func lookupKanaConverterStageByLetter(letter rune) *KanaConverterStageDefinition {
+	kanaConverterStages.RLock()
+	defer kanaConverterStages.RUnlock()
+	return kanaConverterStages.byLetter[letter]
+}
+
+func lookupKanaConverterStageByName(name string) *KanaConverterStageDefinition {
+	kanaConverterStages.RLock()
+	defer kanaConverterStages.RUnlock()
+	return kanaConverterStages.byName[name]
+}
+
+// enableStage enables a registered stage by flag, which is its letter or name.
+// Both of them are recorded to be found by the conflicts.
+func (r *KanaConverterOptions) enableStage(d *KanaConverterStageDefinition, flag string) {
+	r.flags = append(r.flags, d.Name)
+	if d.Letter != 0 {
+		r.flags = append(r.flags, string(d.Letter))
+	}
+	r.stages = append(r.stages, kanaConverterStageBuilder{name: d.Name, newStage: d.NewStage})
+	r.conflicts = append(r.conflicts, kanaConverterConflict{flag: flag, conflicts: d.Conflicts})
+}
+
+// enableName enables the registered stage or mapping named name.
+func (r *KanaConverterOptions) enableName(name string) error {
+	if d := lookupKanaConverterStageByName(name); d != nil {
+		r.enableStage(d, name)
+		return nil
+	}
+	m, err := lookupKanaConverterMapping(name)
+	if err != nil {
+		return err
+	}
+	r.flags = append(r.flags, name)
+	r.stages = append(r.stages, kanaConverterStageBuilder{
+		name: m.name,
+		newStage: func() KanaConverterStage {
+			return m
+		},
+	})
+	return nil
+}
+
+type kanaConverterConflict struct {
+	flag      string
+	conflicts []string
+}
+
+// checkConflicts checks the conflicts of registered stages after the whole
+// mode is parsed, so that they do not depend on the order of the flags.
+func (r *KanaConverterOptions) checkConflicts() error {
+	for _, c := range r.conflicts {
+		for _, flag := range c.conflicts {
+			if r.isEnabled(flag) {
+				return fmt.Errorf("must not combine '%s' and '%s' flags", c.flag, flag)
+			}
+		}
+	}
+	return nil
+}
+
+func (r *KanaConverterOptions) isEnabled(flag string) bool {
+	switch flag {
+	case "r":
+		return r.optr
+	case "R":
+		return r.optR
+	case "n":
+		return r.optn
+	case "N":
+		return r.optN
+	case "a":
+		return r.opta
+	case "A":
+		return r.optA
+	case "s":
+		return r.opts
+	case "S":
+		return r.optS
+	case "k":
+		return r.optk
+	case "K":
+		return r.optK
+	case "h":
+		return r.opth
+	case "H":
+		return r.optH
+	case "c":
+		return r.optc
+	case "C":
+		return r.optC
+	case "V":
+		return r.optV
+	case "M":
+		return r.optM
+	case "m":
+		return r.optm
+	case "j":
+		return r.optj
+	case "J":
+		return r.optJ
+	case "D":
+		return r.optD
+	}
+	for _, f := range r.flags {
+		if f == flag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,207 @@
+package converter_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/ArmadaSuit/udf-go/converter"
+)
+
+var registerTestStagesOnce sync.Once
+
+func registerTestStages() {
+	registerTestStagesOnce.Do(func() {
+		definitions := []converter.KanaConverterStageDefinition{
+			{
+				Name:      "registry_test_wave",
+				Letter:    'ω',
+				Conflicts: []string{"r"},
+				NewStage: func() converter.KanaConverterStage {
+					return converter.KanaConverterFunc(func(dst []converter.KanaConverterRune, r converter.KanaConverterRune) []converter.KanaConverterRune {
+						switch r.Rune {
+						case '〜':
+							r.Rune, r.IsConverted = '～', true
+						case '一':
+							r.Rune, r.IsConverted = '壱', true
+						}
+						return append(dst, r)
+					})
+				},
+			},
+			{
+				Name:      "registry_test_drop",
+				Conflicts: []string{"registry_test_wave"},
+				NewStage: func() converter.KanaConverterStage {
+					return converter.KanaConverterFunc(func(dst []converter.KanaConverterRune, r converter.KanaConverterRune) []converter.KanaConverterRune {
+						if r.Rune == '\u200b' {
+							return dst
+						}
+						return append(dst, r)
+					})
+				},
+			},
+		}
+		for _, d := range definitions {
+			if err := converter.RegisterKanaConverterStage(d); err != nil {
+				panic(err)
+			}
+		}
+	})
+}
+
+func TestRegisterKanaConverterStage(t *testing.T) {
+	registerTestStages()
+
+	newStage := func() converter.KanaConverterStage {
+		return converter.KanaConverterFunc(func(dst []converter.KanaConverterRune, r converter.KanaConverterRune) []converter.KanaConverterRune {
+			return append(dst, r)
+		})
+	}
+	tests := []struct {
+		name string
+		args converter.KanaConverterStageDefinition
+	}{
+		{
+			name: "built-in letter",
+			args: converter.KanaConverterStageDefinition{Name: "registry_test_builtin", Letter: 'K', NewStage: newStage},
+		},
+		{
+			name: "registered letter",
+			args: converter.KanaConverterStageDefinition{Name: "registry_test_letter", Letter: 'ω', NewStage: newStage},
+		},
+		{
+			name: "registered name",
+			args: converter.KanaConverterStageDefinition{Name: "registry_test_wave", NewStage: newStage},
+		},
+		{
+			name: "empty name",
+			args: converter.KanaConverterStageDefinition{NewStage: newStage},
+		},
+		{
+			name: "name with +",
+			args: converter.KanaConverterStageDefinition{Name: "registry+test", NewStage: newStage},
+		},
+		{
+			name: "no NewStage",
+			args: converter.KanaConverterStageDefinition{Name: "registry_test_nil"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+
+			t.Parallel()
+
+			if err := converter.RegisterKanaConverterStage(tt.args); err == nil {
+				t.Errorf("RegisterKanaConverterStage() error = nil, want error")
+			}
+		})
+	}
+}
+
+func TestRegisteredKanaConverterStage(t *testing.T) {
+	registerTestStages()
+
+	type args struct {
+		in   string
+		mode string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "by letter after built-in stages",
+			args: args{in: "ﾎﾞｰﾙ〜一", mode: "KVω"},
+			want: "ボール～壱",
+		},
+		{
+			name: "by name",
+			args: args{in: "ﾎﾞｰﾙ〜一", mode: "KV+registry_test_wave"},
+			want: "ボール～壱",
+		},
+		{
+			name: "without a letter",
+			args: args{in: "a\u200bb", mode: "R+registry_test_drop"},
+			want: "ａｂ",
+		},
+		{
+			name:    "conflict with a built-in letter",
+			args:    args{mode: "ωr"},
+			wantErr: true,
+		},
+		{
+			name:    "conflict with a built-in letter before",
+			args:    args{mode: "rω"},
+			wantErr: true,
+		},
+		{
+			name:    "conflict with a registered stage by letter",
+			args:    args{mode: "ω+registry_test_drop"},
+			wantErr: true,
+		},
+		{
+			name:    "conflict with a registered stage by name",
+			args:    args{mode: "+registry_test_drop+registry_test_wave"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown letter",
+			args:    args{mode: "KVx"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown name",
+			args:    args{mode: "KV+registry_test_unknown"},
+			wantErr: true,
+		},
+		{
+			name:    "empty name",
+			args:    args{mode: "KV+"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+
+			t.Parallel()
+
+			c, err := converter.Compile(tt.args.mode)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Compile() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+			if got := c.Convert(tt.args.in); got != tt.want {
+				t.Errorf("%v is converted %v, want %v", tt.args.in, got, tt.want)
+			}
+			if got, _ := convertByChannel(tt.args.in, tt.args.mode); got != tt.want {
+				t.Errorf("%v is converted %v by channels, want %v", tt.args.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegisteredKanaConverterStageExplain(t *testing.T) {
+	registerTestStages()
+
+	c, err := converter.Compile("KVω")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, traces := c.Explain("ｶ〜")
+	want := []string{"HankakuKatakanaToZenkakuKatakana", "registry_test_wave"}
+	if len(traces) != len(want) {
+		t.Fatalf("Explain() = %v, want stages %v", traces, want)
+	}
+	for i, trace := range traces {
+		if trace.Stage != want[i] {
+			t.Errorf("Explain()[%d].Stage = %v, want %v", i, trace.Stage, want[i])
+		}
+	}
+}
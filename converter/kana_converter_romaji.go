@@ -0,0 +1,577 @@
+package converter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RomajiStyle is a system of romanizing kana.
+type RomajiStyle int
+
+const (
+	// RomajiHepburn writes each kana in Hepburn romanization, such as
+	// とうきょう to toukyou.
+	RomajiHepburn RomajiStyle = iota
+	// RomajiModifiedHepburn writes long vowels with macrons, such as とうきょう
+	// to tōkyō.
+	RomajiModifiedHepburn
+	// RomajiPassport writes in capitals the way of Japanese passports, omitting
+	// long O and U and writing ん before B, M and P as M, such as とうきょう to
+	// TOKYO and なんば to NAMBA.
+	RomajiPassport
+	// RomajiPassportOH is RomajiPassport writing long O as OH, such as おおの to
+	// OHNO.
+	RomajiPassportOH
+	// RomajiKunrei writes each kana in Kunrei-shiki romanization, such as しち to
+	// siti.
+	RomajiKunrei
+)
+
+var romajiStyleNames = []string{"hepburn", "modified_hepburn", "passport", "passport_oh", "kunrei"}
+
+// ParseRomajiStyle returns the style named "hepburn", "modified_hepburn",
+// "passport", "passport_oh" or "kunrei".
+func ParseRomajiStyle(name string) (RomajiStyle, error) {
+	for i, n := range romajiStyleNames {
+		if n == name {
+			return RomajiStyle(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown romaji style %q", name)
+}
+
+func (s RomajiStyle) String() string {
+	if s < 0 || int(s) >= len(romajiStyleNames) {
+		return fmt.Sprintf("RomajiStyle(%d)", int(s))
+	}
+	return romajiStyleNames[s]
+}
+
+// stageName is the name of the registered stage romanizing kana by s.
+func (s RomajiStyle) stageName() string {
+	return "romaji_" + s.String()
+}
+
+// romajiToKanaStageName is the name of the registered stage converting romaji
+// to hiragana.
+const romajiToKanaStageName = "romaji_to_kana"
+
+// the romaji stages are registered to be used in modes, such as
+// "KVM+romaji_hepburn"
+func init() {
+	names := []string{romajiToKanaStageName}
+	for i := range romajiStyleNames {
+		names = append(names, RomajiStyle(i).stageName())
+	}
+	register := func(name string, newStage func() KanaConverterStage) {
+		var conflicts []string
+		for _, n := range names {
+			if n != name {
+				conflicts = append(conflicts, n)
+			}
+		}
+		if err := RegisterKanaConverterStage(KanaConverterStageDefinition{Name: name, Conflicts: conflicts, NewStage: newStage}); err != nil {
+			panic(err)
+		}
+	}
+	for i := range romajiStyleNames {
+		style := RomajiStyle(i)
+		register(style.stageName(), func() KanaConverterStage {
+			return &kanaToRomajiStage{style: style}
+		})
+	}
+	register(romajiToKanaStageName, func() KanaConverterStage {
+		return &romajiToKanaStage{}
+	})
+}
+
+// CompileKanaToRomaji returns a KanaConverter romanizing hiragana and katakana
+// by style. Hankaku katakana and kana followed by voiced marks are composed
+// first, so ｶﾞ is romanized as ガ.
+func CompileKanaToRomaji(style RomajiStyle) (*KanaConverter, error) {
+	if style < 0 || int(style) >= len(romajiStyleNames) {
+		return nil, fmt.Errorf("unknown romaji style %v", style)
+	}
+	return Compile("KVM+" + style.stageName())
+}
+
+// CompileRomajiToKana returns a KanaConverter converting romaji to hiragana as
+// Japanese input methods do, such as kyouto to きょうと.
+func CompileRomajiToKana() (*KanaConverter, error) {
+	return Compile("+" + romajiToKanaStageName)
+}
+
+// KanaToRomaji romanizes zenkaku hiragana and katakana by style.
+func KanaToRomaji(in <-chan KanaConverterRune, style RomajiStyle) <-chan KanaConverterRune {
+	return convertForKanaConverter(&kanaToRomajiStage{style: style}, in)
+}
+
+// RomajiToKana converts romaji to hiragana.
+func RomajiToKana(in <-chan KanaConverterRune) <-chan KanaConverterRune {
+	return convertForKanaConverter(&romajiToKanaStage{}, in)
+}
+
+// romajiHepburnTable maps hiragana and the katakana without hiragana to
+// Hepburn romanization. っ and ー depend on the kana around them.
+var romajiHepburnTable = func() map[string]string {
+	m := map[string]string{}
+	rows := []struct {
+		kana   string
+		romaji []string
+	}{
+		{"あいうえお", []string{"a", "i", "u", "e", "o"}},
+		{"かきくけこ", []string{"ka", "ki", "ku", "ke", "ko"}},
+		{"がぎぐげご", []string{"ga", "gi", "gu", "ge", "go"}},
+		{"さしすせそ", []string{"sa", "shi", "su", "se", "so"}},
+		{"ざじずぜぞ", []string{"za", "ji", "zu", "ze", "zo"}},
+		{"たちつてと", []string{"ta", "chi", "tsu", "te", "to"}},
+		{"だぢづでど", []string{"da", "ji", "zu", "de", "do"}},
+		{"なにぬねの", []string{"na", "ni", "nu", "ne", "no"}},
+		{"はひふへほ", []string{"ha", "hi", "fu", "he", "ho"}},
+		{"ばびぶべぼ", []string{"ba", "bi", "bu", "be", "bo"}},
+		{"ぱぴぷぺぽ", []string{"pa", "pi", "pu", "pe", "po"}},
+		{"まみむめも", []string{"ma", "mi", "mu", "me", "mo"}},
+		{"やゆよ", []string{"ya", "yu", "yo"}},
+		{"らりるれろ", []string{"ra", "ri", "ru", "re", "ro"}},
+		{"わゐゑをん", []string{"wa", "i", "e", "o", "n"}},
+		{"ぁぃぅぇぉ", []string{"xa", "xi", "xu", "xe", "xo"}},
+		{"ゃゅょゎゕゖ", []string{"xya", "xyu", "xyo", "xwa", "ka", "ke"}},
+		{"ゔヷヸヹヺ", []string{"vu", "va", "vi", "ve", "vo"}},
+	}
+	for _, row := range rows {
+		for i, k := range []rune(row.kana) {
+			m[string(k)] = row.romaji[i]
+		}
+	}
+	for k, r := range map[string]string{
+		"ふぁ": "fa", "ふぃ": "fi", "ふぇ": "fe", "ふぉ": "fo", "ふゅ": "fyu",
+		"ゔぁ": "va", "ゔぃ": "vi", "ゔぇ": "ve", "ゔぉ": "vo", "ゔゅ": "vyu",
+		"てぃ": "ti", "でぃ": "di", "てゅ": "tyu", "でゅ": "dyu", "とぅ": "tu", "どぅ": "du",
+		"うぃ": "wi", "うぇ": "we", "うぉ": "wo", "いぇ": "ye",
+		"つぁ": "tsa", "つぃ": "tsi", "つぇ": "tse", "つぉ": "tso",
+		"くぁ": "kwa", "ぐぁ": "gwa",
+	} {
+		m[k] = r
+	}
+	addRomajiYoon(m)
+	return m
+}()
+
+// romajiKunreiTable is romajiHepburnTable in Kunrei-shiki.
+var romajiKunreiTable = func() map[string]string {
+	m := make(map[string]string, len(romajiHepburnTable))
+	for k, r := range romajiHepburnTable {
+		m[k] = r
+	}
+	for k, r := range map[string]string{
+		"し": "si", "じ": "zi", "ち": "ti", "つ": "tu", "ぢ": "zi", "ふ": "hu",
+	} {
+		m[k] = r
+	}
+	addRomajiYoon(m)
+	return m
+}()
+
+// addRomajiYoon adds the kana of the i column followed by small ゃ, ゅ, ぇ and
+// ょ, such as きゃ to kya and しゃ to sha in Hepburn or sya in Kunrei-shiki.
+func addRomajiYoon(m map[string]string) {
+	for _, k := range "きぎしじちぢにひびぴみり" {
+		stem := strings.TrimSuffix(m[string(k)], "i")
+		for i, small := range []rune("ゃゅぇょ") {
+			vowel := []string{"a", "u", "e", "o"}[i]
+			if strings.HasSuffix(stem, "h") || stem == "j" {
+				m[string(k)+string(small)] = stem + vowel
+			} else {
+				m[string(k)+string(small)] = stem + "y" + vowel
+			}
+		}
+	}
+}
+
+func isRomajiKana(r rune) bool {
+	return (r >= 'ぁ' && r <= 'ゖ') || (r >= 'ァ' && r <= 'ヺ') || r == 'ー'
+}
+
+func romajiHiragana(r rune) rune {
+	if r >= 'ァ' && r <= 'ヶ' {
+		return r - 'ァ' + 'ぁ'
+	}
+	return r
+}
+
+// kanaToRomajiStage holds a run of kana until it ends, since っ, ん and long
+// vowels are written by the kana around them.
+type kanaToRomajiStage struct {
+	style RomajiStyle
+	run   []KanaConverterRune
+}
+
+func (s *kanaToRomajiStage) Push(dst []KanaConverterRune, r KanaConverterRune) []KanaConverterRune {
+	if isRomajiKana(r.Rune) {
+		s.run = append(s.run, r)
+		return dst
+	}
+	return append(s.Flush(dst), r)
+}
+
+func (s *kanaToRomajiStage) Flush(dst []KanaConverterRune) []KanaConverterRune {
+	if len(s.run) == 0 {
+		return dst
+	}
+	run := s.run
+	s.run = s.run[:0]
+	return appendRomaji(dst, run, s.style)
+}
+
+// romajiSyllable is the romaji of run[start:end]. It is kept as it is without
+// romaji.
+type romajiSyllable struct {
+	kana       string
+	romaji     string
+	start, end int
+	keep       bool
+}
+
+var romajiMacrons = map[byte]string{'a': "ā", 'i': "ī", 'u': "ū", 'e': "ē", 'o': "ō"}
+
+func appendRomaji(dst []KanaConverterRune, run []KanaConverterRune, style RomajiStyle) []KanaConverterRune {
+	table := romajiHepburnTable
+	if style == RomajiKunrei {
+		table = romajiKunreiTable
+	}
+	passport := style == RomajiPassport || style == RomajiPassportOH
+
+	var syllables []romajiSyllable
+	for i := 0; i < len(run); {
+		k := string(romajiHiragana(run[i].Rune))
+		if i+1 < len(run) {
+			two := k + string(romajiHiragana(run[i+1].Rune))
+			if r, ok := table[two]; ok {
+				syllables = append(syllables, romajiSyllable{kana: two, romaji: r, start: i, end: i + 2})
+				i += 2
+				continue
+			}
+		}
+		r, ok := table[k]
+		syllables = append(syllables, romajiSyllable{kana: k, romaji: r, start: i, end: i + 1, keep: !ok})
+		i++
+	}
+
+	// prev is the last syllable written, to be lengthened or merged into
+	var prev *romajiSyllable
+	for i := range syllables {
+		sy := &syllables[i]
+		next := ""
+		if i+1 < len(syllables) && syllables[i+1].kana != "ん" {
+			next = syllables[i+1].romaji
+		}
+		lastVowel := byte(0)
+		if prev != nil && prev.romaji != "" && strings.IndexByte("aiueo", prev.romaji[len(prev.romaji)-1]) >= 0 {
+			lastVowel = prev.romaji[len(prev.romaji)-1]
+		}
+		merge := func(romaji string) {
+			prev.romaji += romaji
+			prev.end = sy.end
+			sy.romaji = ""
+			sy.keep = false
+		}
+		lengthen := func() {
+			switch {
+			case style == RomajiModifiedHepburn:
+				prev.romaji = prev.romaji[:len(prev.romaji)-1]
+				merge(romajiMacrons[lastVowel])
+			case passport && lastVowel == 'o' && style == RomajiPassportOH:
+				merge("h")
+			case passport:
+				merge("")
+			}
+		}
+
+		switch {
+		case sy.kana == "っ":
+			sy.keep = false
+			switch {
+			case next != "" && strings.IndexByte("aiueo", next[0]) < 0 && style != RomajiKunrei && strings.HasPrefix(next, "ch"):
+				sy.romaji = "t"
+			case next != "" && strings.IndexByte("aiueo", next[0]) < 0:
+				sy.romaji = next[:1]
+			case passport:
+				sy.romaji = ""
+			case style == RomajiKunrei:
+				sy.romaji = "xtu"
+			default:
+				sy.romaji = "xtsu"
+			}
+		case sy.kana == "ん":
+			switch {
+			case passport && next != "" && strings.IndexByte("bmp", next[0]) >= 0:
+				sy.romaji = "m"
+			case !passport && next != "" && strings.IndexByte("aiueoy", next[0]) >= 0:
+				sy.romaji = "n'"
+			}
+		case sy.kana == "ー":
+			if lastVowel == 0 {
+				break
+			}
+			sy.keep = false
+			if style == RomajiHepburn || style == RomajiKunrei {
+				sy.romaji = string(lastVowel)
+				break
+			}
+			lengthen()
+			continue
+		case lastVowel != 0 && prev.kana != "っ" && prev.kana != "ん":
+			long := false
+			switch style {
+			case RomajiModifiedHepburn:
+				long = (lastVowel == 'o' && (sy.kana == "う" || sy.kana == "お")) ||
+					(lastVowel == 'u' && sy.kana == "う") ||
+					(lastVowel == 'a' && sy.kana == "あ") ||
+					(lastVowel == 'e' && sy.kana == "え")
+			case RomajiPassport, RomajiPassportOH:
+				long = (lastVowel == 'o' && (sy.kana == "う" || sy.kana == "お")) ||
+					(lastVowel == 'u' && sy.kana == "う")
+			}
+			if long {
+				lengthen()
+				continue
+			}
+		}
+		prev = sy
+	}
+
+	for _, sy := range syllables {
+		if sy.keep {
+			dst = append(dst, run[sy.start:sy.end]...)
+			continue
+		}
+		if sy.romaji == "" {
+			continue
+		}
+		romaji := sy.romaji
+		if passport {
+			romaji = strings.ToUpper(romaji)
+		}
+		dst = appendKanaConverterSpan(dst, run[sy.start:sy.end], romaji)
+	}
+	return dst
+}
+
+// romajiKanaTable maps romaji to hiragana as Japanese input methods do. ん and
+// っ of doubled consonants are handled by romajiToKanaStage.
+var romajiKanaTable = func() map[string]string {
+	m := map[string]string{}
+	rows := []struct {
+		consonant string
+		kana      []string
+	}{
+		{"", []string{"あ", "い", "う", "え", "お"}},
+		{"k", []string{"か", "き", "く", "け", "こ"}},
+		{"g", []string{"が", "ぎ", "ぐ", "げ", "ご"}},
+		{"s", []string{"さ", "し", "す", "せ", "そ"}},
+		{"z", []string{"ざ", "じ", "ず", "ぜ", "ぞ"}},
+		{"t", []string{"た", "ち", "つ", "て", "と"}},
+		{"d", []string{"だ", "ぢ", "づ", "で", "ど"}},
+		{"n", []string{"な", "に", "ぬ", "ね", "の"}},
+		{"h", []string{"は", "ひ", "ふ", "へ", "ほ"}},
+		{"b", []string{"ば", "び", "ぶ", "べ", "ぼ"}},
+		{"p", []string{"ぱ", "ぴ", "ぷ", "ぺ", "ぽ"}},
+		{"m", []string{"ま", "み", "む", "め", "も"}},
+		{"y", []string{"や", "い", "ゆ", "いぇ", "よ"}},
+		{"r", []string{"ら", "り", "る", "れ", "ろ"}},
+		{"w", []string{"わ", "うぃ", "う", "うぇ", "を"}},
+		{"c", []string{"か", "し", "く", "せ", "こ"}},
+		{"f", []string{"ふぁ", "ふぃ", "ふ", "ふぇ", "ふぉ"}},
+		{"v", []string{"ゔぁ", "ゔぃ", "ゔ", "ゔぇ", "ゔぉ"}},
+		{"j", []string{"じゃ", "じ", "じゅ", "じぇ", "じょ"}},
+		{"sh", []string{"しゃ", "し", "しゅ", "しぇ", "しょ"}},
+		{"ch", []string{"ちゃ", "ち", "ちゅ", "ちぇ", "ちょ"}},
+		{"ts", []string{"つぁ", "つぃ", "つ", "つぇ", "つぉ"}},
+		{"th", []string{"てゃ", "てぃ", "てゅ", "てぇ", "てょ"}},
+		{"dh", []string{"でゃ", "でぃ", "でゅ", "でぇ", "でょ"}},
+		{"tw", []string{"とぁ", "とぃ", "とぅ", "とぇ", "とぉ"}},
+		{"dw", []string{"どぁ", "どぃ", "どぅ", "どぇ", "どぉ"}},
+		{"x", []string{"ぁ", "ぃ", "ぅ", "ぇ", "ぉ"}},
+		{"l", []string{"ぁ", "ぃ", "ぅ", "ぇ", "ぉ"}},
+		{"xy", []string{"ゃ", "ぃ", "ゅ", "ぇ", "ょ"}},
+		{"ly", []string{"ゃ", "ぃ", "ゅ", "ぇ", "ょ"}},
+	}
+	for _, row := range rows {
+		for i, v := range "aiueo" {
+			m[row.consonant+string(v)] = row.kana[i]
+		}
+	}
+	for consonant, k := range map[string]string{
+		"ky": "き", "gy": "ぎ", "sy": "し", "zy": "じ", "jy": "じ", "ty": "ち", "cy": "ち", "dy": "ぢ",
+		"ny": "に", "hy": "ひ", "by": "び", "py": "ぴ", "my": "み", "ry": "り", "fy": "ふ", "vy": "ゔ",
+	} {
+		for i, v := range "aiueo" {
+			m[consonant+string(v)] = k + string([]rune("ゃぃゅぇょ")[i])
+		}
+	}
+	for r, k := range map[string]string{
+		"xtu": "っ", "ltu": "っ", "xtsu": "っ", "ltsu": "っ",
+		"xwa": "ゎ", "lwa": "ゎ", "xka": "ゕ", "lka": "ゕ", "xke": "ゖ", "lke": "ゖ",
+	} {
+		m[r] = k
+	}
+	return m
+}()
+
+// romajiKanaPrefixes are the strings which may be followed by more letters to
+// be a romaji of romajiKanaTable.
+var romajiKanaPrefixes = func() map[string]bool {
+	m := map[string]bool{}
+	for r := range romajiKanaTable {
+		for i := 1; i < len(r); i++ {
+			m[r[:i]] = true
+		}
+	}
+	return m
+}()
+
+// romajiLongVowels are the vowels with macrons or circumflexes, written as two
+// kana such as ō to おう.
+var romajiLongVowels = map[rune]string{
+	'ā': "aa", 'ī': "ii", 'ū': "uu", 'ē': "ee", 'ō': "ou",
+	'â': "aa", 'î': "ii", 'û': "uu", 'ê': "ee", 'ô': "ou",
+}
+
+// romajiLetter returns r as a lowercase hankaku letter, or 0 for the others.
+func romajiLetter(r rune) rune {
+	switch {
+	case r >= 'a' && r <= 'z':
+		return r
+	case r >= 'A' && r <= 'Z':
+		return r - 'A' + 'a'
+	case r >= 'ａ' && r <= 'ｚ':
+		return r - 'ａ' + 'a'
+	case r >= 'Ａ' && r <= 'Ｚ':
+		return r - 'Ａ' + 'a'
+	}
+	return 0
+}
+
+// romajiToKanaStage holds the letters which are not yet a kana, such as k of
+// ka. pending keeps the runes as given, to be passed through when they are
+// not romaji.
+type romajiToKanaStage struct {
+	pending []KanaConverterRune
+	// kana tells that the last rune written is a kana from romaji, for - to be
+	// written as ー
+	kana bool
+}
+
+func (s *romajiToKanaStage) Push(dst []KanaConverterRune, r KanaConverterRune) []KanaConverterRune {
+	if romajiLetter(r.Rune) != 0 {
+		s.pending = append(s.pending, r)
+		return s.resolve(dst, false)
+	}
+	if long, ok := romajiLongVowels[toLowerRomajiLongVowel(r.Rune)]; ok {
+		// the two vowels both come from r
+		for _, v := range long {
+			s.pending = append(s.pending, KanaConverterRune{Rune: v, Start: r.Start, End: r.End, Stage: r.Stage})
+			dst = s.resolve(dst, false)
+		}
+		return dst
+	}
+	if r.Rune == '\'' && len(s.pending) == 1 && romajiLetter(s.pending[0].Rune) == 'n' {
+		dst = appendKanaConverterSpan(dst, []KanaConverterRune{s.pending[0], r}, "ん")
+		s.pending = s.pending[:0]
+		s.kana = true
+		return dst
+	}
+	dst = s.resolve(dst, true)
+	if r.Rune == '-' && s.kana {
+		return append(dst, r.convert('ー'))
+	}
+	s.kana = false
+	return append(dst, r)
+}
+
+func toLowerRomajiLongVowel(r rune) rune {
+	switch r {
+	case 'Ā', 'Ī', 'Ū', 'Ē', 'Ō':
+		return r + 1
+	case 'Â', 'Î', 'Û', 'Ê', 'Ô':
+		return r + 'â' - 'Â'
+	}
+	return r
+}
+
+func (s *romajiToKanaStage) Flush(dst []KanaConverterRune) []KanaConverterRune {
+	dst = s.resolve(dst, true)
+	s.kana = false
+	return dst
+}
+
+// resolve writes the kana of the pending letters as far as they are decided.
+// With final, no more letters follow them.
+func (s *romajiToKanaStage) resolve(dst []KanaConverterRune, final bool) []KanaConverterRune {
+	for len(s.pending) > 0 {
+		var b strings.Builder
+		for _, r := range s.pending {
+			b.WriteRune(romajiLetter(r.Rune))
+		}
+		key := b.String()
+		if k, ok := romajiKanaTable[key]; ok && (final || !romajiKanaPrefixes[key]) {
+			dst = appendKanaConverterSpan(dst, s.pending, k)
+			s.pending, s.kana = s.pending[:0], true
+			continue
+		}
+		if key[0] == 'n' {
+			// n is ん unless a vowel or y follows it, and nn is ん unless a
+			// vowel or y follows it too, so that both konnichiha and konnnichiha
+			// are こんにちは
+			if len(key) == 1 {
+				if !final {
+					return dst
+				}
+				dst = s.appendKana(dst, 1, "ん")
+				continue
+			}
+			if key[1] != 'n' && key[1] != 'y' && strings.IndexByte("aiueo", key[1]) < 0 {
+				dst = s.appendKana(dst, 1, "ん")
+				continue
+			}
+			if key[1] == 'n' {
+				if len(key) == 2 && !final {
+					return dst
+				}
+				if len(key) > 2 && (key[2] == 'y' || strings.IndexByte("aiueo", key[2]) >= 0) {
+					dst = s.appendKana(dst, 1, "ん")
+				} else {
+					dst = s.appendKana(dst, 2, "ん")
+				}
+				continue
+			}
+		}
+		if romajiKanaPrefixes[key] && !final {
+			return dst
+		}
+		if len(key) >= 2 {
+			switch {
+			case key[0] == key[1] && strings.IndexByte("aiueon", key[0]) < 0,
+				key[0] == 't' && key[1] == 'c':
+				dst = s.appendKana(dst, 1, "っ")
+				continue
+			case key[0] == 'm' && (key[1] == 'b' || key[1] == 'p'):
+				dst = s.appendKana(dst, 1, "ん")
+				continue
+			}
+		}
+		// the first letter is not romaji
+		dst = append(dst, s.pending[0])
+		s.pending, s.kana = s.pending[1:], false
+	}
+	s.pending = s.pending[:0]
+	return dst
+}
+
+// appendKana writes kana for the first n pending letters.
+func (s *romajiToKanaStage) appendKana(dst []KanaConverterRune, n int, kana string) []KanaConverterRune {
+	dst = appendKanaConverterSpan(dst, s.pending[:n], kana)
+	s.pending = append(s.pending[:0], s.pending[n:]...)
+	s.kana = true
+	return dst
+}
@@ -0,0 +1,119 @@
+package converter_test
+
+import (
+	"testing"
+
+	"github.com/ArmadaSuit/udf-go/converter"
+)
+
+func TestKanaToRomaji(t *testing.T) {
+	type args struct {
+		in    string
+		style converter.RomajiStyle
+	}
+	tests := []struct {
+		name string
+		args args
+		want string
+	}{
+		{name: "hepburn", args: args{in: "とうきょう、しんじゅく、ちゃづけ", style: converter.RomajiHepburn}, want: "toukyou、shinjuku、chazuke"},
+		{name: "hepburn sokuon", args: args{in: "がっこう、まっちゃ、あっ", style: converter.RomajiHepburn}, want: "gakkou、matcha、axtsu"},
+		{name: "hepburn n before vowels", args: args{in: "けんいち、きんよう、しんぶん", style: converter.RomajiHepburn}, want: "ken'ichi、kin'you、shinbun"},
+		{name: "hepburn katakana", args: args{in: "ラーメン、ファイル、ヴィ", style: converter.RomajiHepburn}, want: "raamen、fairu、vi"},
+		{name: "hepburn hankaku katakana", args: args{in: "ｶﾞｯｺｳ", style: converter.RomajiHepburn}, want: "gakkou"},
+		{name: "hepburn combining marks", args: args{in: "が", style: converter.RomajiHepburn}, want: "ga"},
+		{name: "modified hepburn", args: args{in: "とうきょう、おおさか、くうき、ねえさん、いいえ", style: converter.RomajiModifiedHepburn}, want: "tōkyō、ōsaka、kūki、nēsan、iie"},
+		{name: "modified hepburn prolonged sound marks", args: args{in: "ラーメン、コーヒー", style: converter.RomajiModifiedHepburn}, want: "rāmen、kōhī"},
+		{name: "passport", args: args{in: "さとう、おおの、なんば、はっちょう、けんいち", style: converter.RomajiPassport}, want: "SATO、ONO、NAMBA、HATCHO、KENICHI"},
+		{name: "passport oh", args: args{in: "いとう、おおの、ゆうこ", style: converter.RomajiPassportOH}, want: "ITOH、OHNO、YUKO"},
+		{name: "kunrei", args: args{in: "しち、つづき、ふじ、しゃしん、まっちゃ", style: converter.RomajiKunrei}, want: "siti、tuzuki、huzi、syasin、mattya"},
+		{name: "others are kept", args: args{in: "東京タワー、ABC", style: converter.RomajiHepburn}, want: "東京tawaa、ABC"},
+		{name: "prolonged sound mark alone", args: args{in: "ー", style: converter.RomajiModifiedHepburn}, want: "ー"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+
+			t.Parallel()
+
+			c, err := converter.CompileKanaToRomaji(tt.args.style)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := c.Convert(tt.args.in); got != tt.want {
+				t.Errorf("%v is converted %v, want %v", tt.args.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRomajiToKana(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "youon", in: "kyouto", want: "きょうと"},
+		{name: "sokuon", in: "gakkou matcha", want: "がっこう まっちゃ"},
+		{name: "n", in: "shinbun kanji hon", want: "しんぶん かんじ ほん"},
+		{name: "nn", in: "konnichiha konnnichiha onna", want: "こんにちは こんにちは おんな"},
+		{name: "n with apostrophe", in: "ken'ichi kenichi", want: "けんいち けにち"},
+		{name: "kunrei", in: "siti tuduki huzi", want: "しち つづき ふじ"},
+		{name: "passport", in: "NAMBA SATO", want: "なんば さと"},
+		{name: "macrons", in: "Tōkyō", want: "とうきょう"},
+		{name: "zenkaku", in: "ｓｕｓｈｉ", want: "すし"},
+		{name: "prolonged sound mark", in: "ra-men 1-2", want: "らーめん 1-2"},
+		{name: "hyphen at the start", in: "-a", want: "-あ"},
+		{name: "not romaji", in: "xyz q 東京", want: "xyz q 東京"},
+		{name: "small kana", in: "fairu xtu ltsu", want: "ふぁいる っ っ"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+
+			t.Parallel()
+
+			c, err := converter.CompileRomajiToKana()
+			if err != nil {
+				t.Fatal(err)
+			}
+			// twice to see that nothing is left from the first conversion
+			for i := 0; i < 2; i++ {
+				if got := c.Convert(tt.in); got != tt.want {
+					t.Errorf("%v is converted %v, want %v", tt.in, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseRomajiStyle(t *testing.T) {
+	for _, name := range []string{"hepburn", "modified_hepburn", "passport", "passport_oh", "kunrei"} {
+		style, err := converter.ParseRomajiStyle(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if style.String() != name {
+			t.Errorf("ParseRomajiStyle(%v) = %v", name, style)
+		}
+	}
+	if _, err := converter.ParseRomajiStyle("nihon"); err == nil {
+		t.Error("ParseRomajiStyle(nihon) error = nil")
+	}
+}
+
+func TestRomajiStagesInModes(t *testing.T) {
+	if _, err := converter.Compile("+romaji_hepburn+romaji_to_kana"); err == nil {
+		t.Error("romaji stages are combined")
+	}
+	c, err := converter.Compile("KV+romaji_kunrei")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := c.Convert("ｼﾞｼﾝ"); got != "zisin" {
+		t.Errorf("ｼﾞｼﾝ is converted %v, want zisin", got)
+	}
+	if _, err := c.Inverse(); err == nil {
+		t.Error("romaji stages have an inverse")
+	}
+}
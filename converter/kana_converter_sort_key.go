@@ -0,0 +1,186 @@
+package converter
+
+import (
+	"sync"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// kanaSortKeyMode folds the text for KanaSortKey, so that hankaku and zenkaku
+// forms and hiragana and katakana sort alike.
+const kanaSortKeyMode = "asKVCM"
+
+var (
+	kanaSortKeyOnce      sync.Once
+	kanaSortKeyConverter *KanaConverter
+)
+
+// kanaSortKeyBases are the katakana in gojūon order, each of which is the
+// primary weight of itself and of its voiced, semi-voiced and small forms.
+const kanaSortKeyBases = "アイウエオカキクケコサシスセソタチツテトナニヌネノハヒフヘホマミムメモヤユヨラリルレロワヰヱヲン"
+
+// kanaSortKeyVowels are the vowels of kanaSortKeyBases in the same order,
+// which ー is weighted as.
+const kanaSortKeyVowels = "アイウエオアイウエオアイウエオアイウエオアイウエオアイウエオアイウエオアウオアイウエオアイエオン"
+
+var kanaSortKeySmall = map[rune]rune{
+	'ァ': 'ア', 'ィ': 'イ', 'ゥ': 'ウ', 'ェ': 'エ', 'ォ': 'オ', 'ッ': 'ツ',
+	'ャ': 'ヤ', 'ュ': 'ユ', 'ョ': 'ヨ', 'ヮ': 'ワ', 'ヵ': 'カ', 'ヶ': 'ケ',
+}
+
+// kanaSortKeyBaseWeights maps the bases to their primary weights, and
+// kanaSortKeyVowelWeights maps the primary weights to the ones of the vowels.
+var (
+	kanaSortKeyBaseWeights  = map[rune]uint32{}
+	kanaSortKeyVowelWeights = map[uint32]uint32{}
+)
+
+func init() {
+	for i, r := range []rune(kanaSortKeyBases) {
+		kanaSortKeyBaseWeights[r] = uint32(i + 1)
+	}
+	for i, r := range []rune(kanaSortKeyVowels) {
+		kanaSortKeyVowelWeights[uint32(i+1)] = kanaSortKeyBaseWeights[r]
+	}
+}
+
+// The classes of primary weights, which sort symbols, digits, Latin letters,
+// kana and the others, such as kanji, in this order.
+const (
+	kanaSortKeySymbol byte = iota + 1
+	kanaSortKeyDigit
+	kanaSortKeyLatin
+	kanaSortKeyKana
+	kanaSortKeyOther
+)
+
+// The secondary weights of kana.
+const (
+	kanaSortKeyUnvoiced byte = iota + 1
+	kanaSortKeyVoiced
+	kanaSortKeySemiVoiced
+)
+
+// The tertiary weights of kana.
+const (
+	kanaSortKeyLarge byte = iota + 1
+	kanaSortKeySmallForm
+	kanaSortKeyProlonged
+)
+
+// The tertiary weights of Latin letters.
+const (
+	kanaSortKeyLower byte = iota + 1
+	kanaSortKeyUpper
+)
+
+// kanaSortKeyElement is the weights of a rune at each level.
+type kanaSortKeyElement struct {
+	class     byte
+	primary   uint32
+	secondary byte
+	tertiary  byte
+}
+
+// KanaSortKey returns a key of s which sorts as a Japanese dictionary does
+// when compared byte by byte, so it can be stored and indexed. Hankaku and
+// zenkaku forms and hiragana and katakana are folded first. Kana sort in
+// gojūon order, voiced and semi-voiced kana after the unvoiced ones and small
+// kana after the large ones, and ー is weighted as the vowel before it, so
+// that はは < はば < ばは < ぱぱ and かあ < かー < かい. Symbols, digits, Latin
+// letters in any case, kana and the others, such as kanji in code point
+// order, sort in this order.
+//
+// The key holds the primary weights of the runes, each of 4 bytes, then a
+// 0 byte and the secondary weights, then a 0 byte and the tertiary weights.
+func KanaSortKey(s string) []byte {
+	kanaSortKeyOnce.Do(func() {
+		c, err := Compile(kanaSortKeyMode)
+		if err != nil {
+			panic(err)
+		}
+		kanaSortKeyConverter = c
+	})
+
+	var elements []kanaSortKeyElement
+	var last kanaSortKeyElement
+	for _, r := range kanaSortKeyConverter.Convert(s) {
+		e, ok := kanaSortKeyKanaElement(r)
+		switch {
+		case ok:
+		case r == 'ー' && last.class == kanaSortKeyKana:
+			e = kanaSortKeyElement{class: kanaSortKeyKana, primary: kanaSortKeyVowelWeights[last.primary], tertiary: kanaSortKeyProlonged}
+		case (r == 'ヽ' || r == 'ヾ') && last.class == kanaSortKeyKana:
+			// an iteration mark repeats the kana before it
+			e = last
+			e.secondary, e.tertiary = kanaSortKeyUnvoiced, kanaSortKeyLarge
+			if r == 'ヾ' {
+				e.secondary = kanaSortKeyVoiced
+			}
+		case unicode.IsDigit(r):
+			e = kanaSortKeyElement{class: kanaSortKeyDigit, primary: uint32(r)}
+		case unicode.IsUpper(r) && unicode.In(r, unicode.Latin):
+			e = kanaSortKeyElement{class: kanaSortKeyLatin, primary: uint32(unicode.ToLower(r)), tertiary: kanaSortKeyUpper}
+		case unicode.In(r, unicode.Latin):
+			e = kanaSortKeyElement{class: kanaSortKeyLatin, primary: uint32(r), tertiary: kanaSortKeyLower}
+		case unicode.IsLetter(r):
+			e = kanaSortKeyElement{class: kanaSortKeyOther, primary: uint32(r)}
+		default:
+			e = kanaSortKeyElement{class: kanaSortKeySymbol, primary: uint32(r)}
+		}
+		// the weights of the others are 1 at the lower levels
+		if e.secondary == 0 {
+			e.secondary = 1
+		}
+		if e.tertiary == 0 {
+			e.tertiary = 1
+		}
+		elements = append(elements, e)
+		last = e
+	}
+
+	key := make([]byte, 0, len(elements)*6+2)
+	for _, e := range elements {
+		key = append(key, e.class, byte(e.primary>>16), byte(e.primary>>8), byte(e.primary))
+	}
+	key = append(key, 0)
+	for _, e := range elements {
+		key = append(key, e.secondary)
+	}
+	key = append(key, 0)
+	for _, e := range elements {
+		key = append(key, e.tertiary)
+	}
+	return key
+}
+
+// kanaSortKeyKanaElement returns the weights of r if it is katakana, decomposed
+// into the base and a voiced or semi-voiced mark.
+func kanaSortKeyKanaElement(r rune) (kanaSortKeyElement, bool) {
+	if r >= 'ゔ' && r <= 'ゖ' {
+		// 'C' passes ゔ, ゕ and ゖ through
+		r += 'ァ' - 'ぁ'
+	}
+	e := kanaSortKeyElement{class: kanaSortKeyKana, secondary: kanaSortKeyUnvoiced, tertiary: kanaSortKeyLarge}
+	d := []rune(norm.NFD.String(string(r)))
+	if len(d) == 2 {
+		switch d[1] {
+		case '\u3099':
+			e.secondary = kanaSortKeyVoiced
+		case '\u309a':
+			e.secondary = kanaSortKeySemiVoiced
+		default:
+			return e, false
+		}
+	} else if len(d) != 1 {
+		return e, false
+	}
+	base := d[0]
+	if b, ok := kanaSortKeySmall[base]; ok {
+		base, e.tertiary = b, kanaSortKeySmallForm
+	}
+	w, ok := kanaSortKeyBaseWeights[base]
+	e.primary = w
+	return e, ok
+}
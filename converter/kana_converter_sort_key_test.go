@@ -0,0 +1,82 @@
+package converter_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ArmadaSuit/udf-go/converter"
+)
+
+func TestKanaSortKey(t *testing.T) {
+	type args struct {
+		less    string
+		greater string
+	}
+	tests := []struct {
+		name string
+		args args
+	}{
+		{name: "gojuon", args: args{less: "あいうえお", greater: "かきくけこ"}},
+		{name: "prefix", args: args{less: "さと", greater: "さとう"}},
+		{name: "voiced after unvoiced", args: args{less: "はば", greater: "ばは"}},
+		{name: "semi-voiced after voiced", args: args{less: "ばば", greater: "ぱぱ"}},
+		{name: "voicing after the primary", args: args{less: "ぱぱ", greater: "はひ"}},
+		{name: "small after large", args: args{less: "きよう", greater: "きょう"}},
+		{name: "small before the next kana", args: args{less: "きょう", greater: "きよお"}},
+		{name: "prolonged sound mark as the vowel", args: args{less: "カア", greater: "カー"}},
+		{name: "prolonged sound mark before the next vowel", args: args{less: "カー", greater: "カイ"}},
+		{name: "prolonged sound mark after small kana", args: args{less: "ニュー", greater: "ニュエ"}},
+		{name: "iteration mark", args: args{less: "ささき", greater: "さゝみ"}},
+		{name: "voiced iteration mark", args: args{less: "すすき", greater: "すゞき"}},
+		{name: "wa row", args: args{less: "わ", greater: "を"}},
+		{name: "n last", args: args{less: "を", greater: "ん"}},
+		{name: "symbols before digits", args: args{less: "-1", greater: "1"}},
+		{name: "digits before latin", args: args{less: "9", greater: "a"}},
+		{name: "latin in any case", args: args{less: "Apple", greater: "banana"}},
+		{name: "lower case before upper case", args: args{less: "apple", greater: "Apple"}},
+		{name: "latin before kana", args: args{less: "z", greater: "あ"}},
+		{name: "kana before kanji", args: args{less: "ん", greater: "亜"}},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+
+			t.Parallel()
+
+			less, greater := converter.KanaSortKey(tt.args.less), converter.KanaSortKey(tt.args.greater)
+			if bytes.Compare(less, greater) >= 0 {
+				t.Errorf("%v is sorted after %v, want before (%x, %x)", tt.args.less, tt.args.greater, less, greater)
+			}
+		})
+	}
+}
+
+func TestKanaSortKeyFolding(t *testing.T) {
+	type args struct {
+		s []string
+	}
+	tests := []struct {
+		name string
+		args args
+	}{
+		{name: "width and kana", args: args{s: []string{"ﾀﾅｶ ﾀﾛｳ", "たなか　たろう", "タナカ タロウ"}}},
+		{name: "voiced marks", args: args{s: []string{"ｶﾞｯｺｳ", "がっこう", "か\u3099っこう", "カ゛ッコウ"}}},
+		{name: "prolonged sound mark", args: args{s: []string{"ｺｰﾋｰ", "コーヒー", "こーひー"}}},
+		{name: "ゔ and small ゕ and ゖ", args: args{s: []string{"ヴヵヶ", "ゔゕゖ"}}},
+		{name: "latin and digits", args: args{s: []string{"ＡＢＣ１２３", "ABC123"}}},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+
+			t.Parallel()
+
+			want := converter.KanaSortKey(tt.args.s[0])
+			for _, s := range tt.args.s[1:] {
+				if got := converter.KanaSortKey(s); !bytes.Equal(got, want) {
+					t.Errorf("the key of %v is %x, want %x as %v", s, got, want, tt.args.s[0])
+				}
+			}
+		})
+	}
+}
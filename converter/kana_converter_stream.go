@@ -0,0 +1,130 @@
+package converter
+
+import (
+	"io"
+	"unicode/utf8"
+)
+
+const kanaConverterReaderBufferSize = 4096
+
+// kanaConverterStream converts input that arrives in chunks. Stage state and
+// an incomplete UTF-8 sequence at the end of a chunk are carried over to the
+// next one, so the output is the same as converting the whole input at once.
+type kanaConverterStream struct {
+	stages   []KanaConverterStage
+	pending  []byte
+	offset   int
+	src, tmp []KanaConverterRune
+}
+
+// convert appends the conversion of p to dst. final must be true for the last
+// chunk to flush the carried over state.
+func (s *kanaConverterStream) convert(dst, p []byte, final bool) []byte {
+	buf := p
+	if len(s.pending) > 0 {
+		buf = append(s.pending, p...)
+	}
+	n := len(buf)
+	if !final {
+		for n = 0; n < len(buf) && utf8.FullRune(buf[n:]); {
+			_, size := utf8.DecodeRune(buf[n:])
+			n += size
+		}
+	}
+	in := appendKanaConverterBytes(s.src[:0], buf[:n], s.offset)
+	s.offset += n
+	s.pending = append(s.pending[:0], buf[n:]...)
+
+	out, spare := runKanaConverterStages(s.stages, in, s.tmp, final)
+	s.src, s.tmp = out, spare
+	for _, r := range out {
+		dst = utf8.AppendRune(dst, r.Rune)
+	}
+	return dst
+}
+
+// KanaConverterReader converts the text read from an underlying reader.
+type KanaConverterReader struct {
+	r      io.Reader
+	stream kanaConverterStream
+	buf    []byte
+	out    []byte
+	off    int
+	err    error
+}
+
+// NewReader returns a reader that converts the text read from r by mode.
+func NewReader(r io.Reader, mode string) (*KanaConverterReader, error) {
+	c, err := Compile(mode)
+	if err != nil {
+		return nil, err
+	}
+	return c.NewReader(r), nil
+}
+
+// NewReader returns a reader that converts the text read from r.
+func (c *KanaConverter) NewReader(r io.Reader) *KanaConverterReader {
+	return &KanaConverterReader{
+		r:      r,
+		stream: kanaConverterStream{stages: c.stages()},
+		buf:    make([]byte, kanaConverterReaderBufferSize),
+	}
+}
+
+func (r *KanaConverterReader) Read(p []byte) (int, error) {
+	for r.off == len(r.out) {
+		if r.err != nil {
+			return 0, r.err
+		}
+		n, err := r.r.Read(r.buf)
+		r.out = r.stream.convert(r.out[:0], r.buf[:n], err != nil)
+		r.off = 0
+		r.err = err
+	}
+	n := copy(p, r.out[r.off:])
+	r.off += n
+	return n, nil
+}
+
+// KanaConverterWriter converts the text written to it and writes the result
+// to an underlying writer. Close must be called to write the end of the text.
+type KanaConverterWriter struct {
+	w      io.Writer
+	stream kanaConverterStream
+	out    []byte
+}
+
+// NewWriter returns a writer that converts the text written to it by mode and
+// writes the result to w.
+func NewWriter(w io.Writer, mode string) (*KanaConverterWriter, error) {
+	c, err := Compile(mode)
+	if err != nil {
+		return nil, err
+	}
+	return c.NewWriter(w), nil
+}
+
+// NewWriter returns a writer that converts the text written to it and writes
+// the result to w.
+func (c *KanaConverter) NewWriter(w io.Writer) *KanaConverterWriter {
+	return &KanaConverterWriter{
+		w:      w,
+		stream: kanaConverterStream{stages: c.stages()},
+	}
+}
+
+func (w *KanaConverterWriter) Write(p []byte) (int, error) {
+	w.out = w.stream.convert(w.out[:0], p, false)
+	if _, err := w.w.Write(w.out); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close writes the runes held back for the end of the text. It does not close
+// the underlying writer.
+func (w *KanaConverterWriter) Close() error {
+	w.out = w.stream.convert(w.out[:0], nil, true)
+	_, err := w.w.Write(w.out)
+	return err
+}
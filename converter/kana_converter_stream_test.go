@@ -0,0 +1,127 @@
+package converter_test
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/ArmadaSuit/udf-go/converter"
+)
+
+var streamTests = []struct {
+	name string
+	in   string
+	mode string
+}{
+	{
+		name: "voiced mark split from hankaku katakana",
+		in:   "ﾎﾞｰﾙﾍﾟﾝｶﾞｷﾞｸﾞ",
+		mode: "KV",
+	},
+	{
+		name: "voiced mark split from hankaku katakana into hiragana",
+		in:   "ﾎﾞｰﾙﾍﾟﾝｶﾞｷﾞｸﾞ",
+		mode: "HV",
+	},
+	{
+		name: "dangling hankaku katakana at the end",
+		in:   "ｱｲｳｴｵｶ",
+		mode: "KV",
+	},
+	{
+		name: "mixed",
+		in:   "「ﾎﾞｰﾙﾍﾟﾝの芯の太さは、０．７ｍｍです。」　The quick brown fox",
+		mode: "KVas",
+	},
+	{
+		name: "invalid utf-8",
+		in:   "ｶ\xe3\x82ﾞ\xff\xe3",
+		mode: "KV",
+	},
+}
+
+func TestKanaConverterReader(t *testing.T) {
+	for _, tt := range streamTests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+
+			t.Parallel()
+
+			c, err := converter.Compile(tt.mode)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := c.Convert(tt.in)
+
+			for _, r := range []io.Reader{
+				strings.NewReader(tt.in),
+				iotest.OneByteReader(strings.NewReader(tt.in)),
+				iotest.DataErrReader(iotest.HalfReader(strings.NewReader(tt.in))),
+			} {
+				got, err := io.ReadAll(c.NewReader(r))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(got) != want {
+					t.Errorf("%v is converted %v, want %v", tt.in, string(got), want)
+				}
+			}
+		})
+	}
+}
+
+func TestKanaConverterWriter(t *testing.T) {
+	for _, tt := range streamTests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+
+			t.Parallel()
+
+			want := converter.ConvertForKanaConverter(tt.in, mustNewKanaConverterStages(t, tt.mode))
+
+			for _, size := range []int{1, 2, 3, len(tt.in)} {
+				var b bytes.Buffer
+				w, err := converter.NewWriter(&b, tt.mode)
+				if err != nil {
+					t.Fatal(err)
+				}
+				for in := []byte(tt.in); len(in) > 0; {
+					n := size
+					if n > len(in) {
+						n = len(in)
+					}
+					if _, err := w.Write(in[:n]); err != nil {
+						t.Fatal(err)
+					}
+					in = in[n:]
+				}
+				if err := w.Close(); err != nil {
+					t.Fatal(err)
+				}
+				if got := b.String(); got != want {
+					t.Errorf("%v is converted %v by %d bytes, want %v", tt.in, got, size, want)
+				}
+			}
+		})
+	}
+}
+
+func TestNewReader(t *testing.T) {
+	if _, err := converter.NewReader(strings.NewReader(""), "kK"); err == nil {
+		t.Errorf("NewReader() error = nil, want error")
+	}
+	if _, err := converter.NewWriter(io.Discard, "kK"); err == nil {
+		t.Errorf("NewWriter() error = nil, want error")
+	}
+}
+
+func mustNewKanaConverterStages(t *testing.T, mode string) []converter.KanaConverterStage {
+	t.Helper()
+	stages, err := converter.NewKanaConverterStages(mode)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return stages
+}
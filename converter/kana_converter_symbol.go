@@ -0,0 +1,58 @@
+package converter
+
+// symbolStages convert the characters which 'a' and 'A' skip following
+// mb_convert_kana. The names starting with "a_" convert the zenkaku forms and
+// the characters used alike to hankaku, and the names starting with "A_"
+// convert the hankaku characters to the zenkaku form chosen by the name.
+var symbolStages = []struct {
+	name string
+	// symbol is the hankaku character, and the stages converting the same
+	// symbol must not be combined
+	symbol rune
+	from   []rune
+	to     rune
+}{
+	{name: "a_quote", symbol: '"', from: []rune{'＂', '“', '”'}, to: '"'},
+	{name: "a_apostrophe", symbol: '\'', from: []rune{'＇', '‘', '’'}, to: '\''},
+	{name: "a_backslash", symbol: '\\', from: []rune{'＼', '￥'}, to: '\\'},
+	{name: "a_tilde", symbol: '~', from: []rune{'～', '〜'}, to: '~'},
+	{name: "A_quote", symbol: '"', from: []rune{'"'}, to: '＂'},
+	{name: "A_quote_right", symbol: '"', from: []rune{'"'}, to: '”'},
+	{name: "A_apostrophe", symbol: '\'', from: []rune{'\''}, to: '＇'},
+	{name: "A_apostrophe_right", symbol: '\'', from: []rune{'\''}, to: '’'},
+	{name: "A_backslash", symbol: '\\', from: []rune{'\\'}, to: '＼'},
+	{name: "A_yen", symbol: '\\', from: []rune{'\\'}, to: '￥'},
+	{name: "A_tilde", symbol: '~', from: []rune{'~'}, to: '～'},
+	{name: "A_wave_dash", symbol: '~', from: []rune{'~'}, to: '〜'},
+}
+
+// the symbol stages are registered to be used in modes, such as
+// "a+a_backslash+a_tilde" or "A+A_yen+A_wave_dash"
+func init() {
+	for _, s := range symbolStages {
+		s := s
+		var conflicts []string
+		for _, c := range symbolStages {
+			if c.symbol == s.symbol && c.name != s.name {
+				conflicts = append(conflicts, c.name)
+			}
+		}
+		convert := KanaConverterFunc(func(dst []KanaConverterRune, r KanaConverterRune) []KanaConverterRune {
+			for _, from := range s.from {
+				if r.Rune == from {
+					return append(dst, r.convert(s.to))
+				}
+			}
+			return append(dst, r)
+		})
+		if err := RegisterKanaConverterStage(KanaConverterStageDefinition{
+			Name:      s.name,
+			Conflicts: conflicts,
+			NewStage: func() KanaConverterStage {
+				return convert
+			},
+		}); err != nil {
+			panic(err)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package converter_test
+
+import (
+	"testing"
+
+	"github.com/ArmadaSuit/udf-go/converter"
+)
+
+func TestSymbolStages(t *testing.T) {
+	type args struct {
+		in   string
+		mode string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "skipped by a",
+			args: args{in: "Ａ＂＇＼～", mode: "a"},
+			want: "A＂＇＼～",
+		},
+		{
+			name: "hankaku",
+			args: args{in: "Ａ＂“”＇‘’＼￥～〜", mode: "a+a_quote+a_apostrophe+a_backslash+a_tilde"},
+			want: "A\"\"\"'''\\\\~~",
+		},
+		{
+			name: "skipped by A",
+			args: args{in: "A\"'\\~", mode: "A"},
+			want: "Ａ\"'\\~",
+		},
+		{
+			name: "zenkaku",
+			args: args{in: "A\"'\\~", mode: "A+A_quote+A_apostrophe+A_backslash+A_tilde"},
+			want: "Ａ＂＇＼～",
+		},
+		{
+			name: "zenkaku alternatives",
+			args: args{in: "A\"'\\~", mode: "A+A_quote_right+A_apostrophe_right+A_yen+A_wave_dash"},
+			want: "Ａ”’￥〜",
+		},
+		{
+			name: "only the symbols given",
+			args: args{in: "\"\\", mode: "+A_yen"},
+			want: "\"￥",
+		},
+		{
+			name:    "two targets of a symbol",
+			args:    args{mode: "A+A_backslash+A_yen"},
+			wantErr: true,
+		},
+		{
+			name:    "both directions of a symbol",
+			args:    args{mode: "+a_tilde+A_tilde"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+
+			t.Parallel()
+
+			c, err := converter.Compile(tt.args.mode)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Compile() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+			if got := c.Convert(tt.args.in); got != tt.want {
+				t.Errorf("%v is converted %v, want %v", tt.args.in, got, tt.want)
+			}
+			if got, _ := convertByChannel(tt.args.in, tt.args.mode); got != tt.want {
+				t.Errorf("%v is converted %v by channels, want %v", tt.args.in, got, tt.want)
+			}
+		})
+	}
+}
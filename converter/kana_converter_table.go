@@ -0,0 +1,213 @@
+package converter
+
+// kanaConverterTableRanges lists the runes that built-in stages may change.
+// Runes outside of them are passed through a kanaConverterTable unchanged.
+var kanaConverterTableRanges = [][2]rune{
+	{0x0000, 0x007F},
+	{0x3000, 0x30FF},
+	{0x31F0, 0x31FF},
+	{0xFF00, 0xFFEF},
+}
+
+type kanaConverterTableKind uint8
+
+const (
+	// the rune flushes a held base and is replaced by out
+	kanaConverterTableFlush kanaConverterTableKind = iota
+	// the rune was converted before the voiced mark composition, which flushes
+	// a held base and passes it through
+	kanaConverterTableKeep
+	// the rune is held until the next rune to be composed with a voiced mark
+	kanaConverterTableBase
+	// the rune is a voiced mark (ﾞ)
+	kanaConverterTableVoicedMark
+	// the rune is a semi-voiced mark (ﾟ)
+	kanaConverterTableSemiVoicedMark
+)
+
+type kanaConverterTableEntry struct {
+	kind kanaConverterTableKind
+	out  []KanaConverterRune
+	// composed holds the output of a base followed by ﾞ and ﾟ
+	composed *[2][]KanaConverterRune
+}
+
+// kanaConverterTable is the result of folding a mode's stages, so that each
+// rune is looked up once instead of passing through every stage.
+type kanaConverterTable struct {
+	blocks []*[256]kanaConverterTableEntry
+}
+
+// newKanaConverterTable folds stages into a table. It reports false if the
+// stages cannot be folded, that is when some of them are not built-in.
+func newKanaConverterTable(stages []KanaConverterStage) (*kanaConverterTable, bool) {
+	composer := -1
+	for i, s := range stages {
+		switch s := s.(type) {
+		case KanaConverterFunc:
+		case *hankakuKatakanaToZenkakuKatakanaStage, *hankakuKatakanaToZenkakuHiraganaStage:
+			if isKanaConverterComposer(s) {
+				if composer >= 0 {
+					return nil, false
+				}
+				composer = i
+			}
+		default:
+			return nil, false
+		}
+	}
+
+	t := &kanaConverterTable{}
+	for _, rng := range kanaConverterTableRanges {
+		for r := rng[0]; r <= rng[1]; r++ {
+			e, ok := newKanaConverterTableEntry(stages, composer, r)
+			if !ok {
+				return nil, false
+			}
+			if e.kind == kanaConverterTableFlush && len(e.out) == 1 && e.out[0] == (KanaConverterRune{Rune: r, End: 1}) {
+				continue
+			}
+			t.set(r, e)
+		}
+	}
+	return t, true
+}
+
+// newKanaConverterTableEntry simulates the stages for r placed at [0, 1) of the
+// input and, for a base, followed by a voiced mark at [1, 2).
+func newKanaConverterTableEntry(stages []KanaConverterStage, composer int, r rune) (kanaConverterTableEntry, bool) {
+	in := []KanaConverterRune{{Rune: r, End: 1}}
+	if composer < 0 {
+		return kanaConverterTableEntry{out: applyKanaConverterStages(stages, in)}, true
+	}
+
+	prefix, c, suffix := stages[:composer], stages[composer], stages[composer+1:]
+	p := applyKanaConverterStages(prefix, in)
+	switch {
+	case len(p) != 1:
+		// the composition depends on the order of the runes in p
+		return kanaConverterTableEntry{}, false
+	case p[0].IsConverted:
+		return kanaConverterTableEntry{kind: kanaConverterTableKeep, out: applyKanaConverterStages(suffix, p)}, true
+	}
+
+	compose := func(in ...KanaConverterRune) []KanaConverterRune {
+		return applyKanaConverterStages(suffix, applyKanaConverterStages([]KanaConverterStage{c}, in))
+	}
+	e := kanaConverterTableEntry{out: compose(p[0])}
+	voicedMark := KanaConverterRune{Rune: 'ﾞ', Start: 1, End: 2}
+	semiVoicedMark := KanaConverterRune{Rune: 'ﾟ', Start: 1, End: 2}
+	// whether the base is composed is told before the stages after, which may
+	// split the composed rune again
+	isComposed := func(mark KanaConverterRune) bool {
+		return len(applyKanaConverterStages([]KanaConverterStage{c}, []KanaConverterRune{p[0], mark})) == 1
+	}
+	switch {
+	case p[0].Rune == 'ﾞ':
+		e.kind = kanaConverterTableVoicedMark
+	case p[0].Rune == 'ﾟ':
+		e.kind = kanaConverterTableSemiVoicedMark
+	case isComposed(voicedMark) || isComposed(semiVoicedMark):
+		e.kind = kanaConverterTableBase
+		e.composed = &[2][]KanaConverterRune{compose(p[0], voicedMark), compose(p[0], semiVoicedMark)}
+	}
+	return e, true
+}
+
+func isKanaConverterComposer(s KanaConverterStage) bool {
+	switch s := s.(type) {
+	case *hankakuKatakanaToZenkakuKatakanaStage:
+		return s.v
+	case *hankakuKatakanaToZenkakuHiraganaStage:
+		return s.v
+	}
+	return false
+}
+
+func applyKanaConverterStages(stages []KanaConverterStage, in []KanaConverterRune) []KanaConverterRune {
+	out, _ := runKanaConverterStages(stages, append([]KanaConverterRune(nil), in...), nil, true)
+	return out
+}
+
+func (t *kanaConverterTable) set(r rune, e kanaConverterTableEntry) {
+	i := int(r >> 8)
+	if i >= len(t.blocks) {
+		t.blocks = append(t.blocks, make([]*[256]kanaConverterTableEntry, i+1-len(t.blocks))...)
+	}
+	if t.blocks[i] == nil {
+		t.blocks[i] = &[256]kanaConverterTableEntry{}
+		for j := range t.blocks[i] {
+			c := r&^0xFF | rune(j)
+			t.blocks[i][j] = kanaConverterTableEntry{out: []KanaConverterRune{{Rune: c, End: 1}}}
+		}
+	}
+	t.blocks[i][r&0xFF] = e
+}
+
+// lookup returns nil for the runes that are passed through unchanged.
+func (t *kanaConverterTable) lookup(r rune) *kanaConverterTableEntry {
+	i := int(r >> 8)
+	if i >= len(t.blocks) || t.blocks[i] == nil {
+		return nil
+	}
+	return &t.blocks[i][r&0xFF]
+}
+
+// kanaConverterTableStage is a KanaConverterStage running a kanaConverterTable.
+type kanaConverterTableStage struct {
+	table    *kanaConverterTable
+	held     *kanaConverterTableEntry
+	heldRune KanaConverterRune
+}
+
+func (s *kanaConverterTableStage) Push(dst []KanaConverterRune, r KanaConverterRune) []KanaConverterRune {
+	if r.IsConverted {
+		return append(s.Flush(dst), r)
+	}
+	e := s.table.lookup(r.Rune)
+	if e == nil {
+		return append(s.Flush(dst), r)
+	}
+	switch e.kind {
+	case kanaConverterTableKeep:
+		return appendKanaConverterTableRunes(s.Flush(dst), e.out, r, r)
+	case kanaConverterTableBase:
+		dst = s.Flush(dst)
+		s.held, s.heldRune = e, r
+		return dst
+	case kanaConverterTableVoicedMark, kanaConverterTableSemiVoicedMark:
+		if s.held != nil {
+			dst = appendKanaConverterTableRunes(dst, s.held.composed[e.kind-kanaConverterTableVoicedMark], s.heldRune, r)
+			s.held = nil
+			return dst
+		}
+	}
+	return appendKanaConverterTableRunes(s.Flush(dst), e.out, r, r)
+}
+
+func (s *kanaConverterTableStage) Flush(dst []KanaConverterRune) []KanaConverterRune {
+	if s.held != nil {
+		dst = appendKanaConverterTableRunes(dst, s.held.out, s.heldRune, s.heldRune)
+		s.held = nil
+	}
+	return dst
+}
+
+// appendKanaConverterTableRunes appends the runes of a table entry to dst,
+// moving the positions simulated at [0, 1) and [1, 2) to first and second.
+func appendKanaConverterTableRunes(dst, out []KanaConverterRune, first, second KanaConverterRune) []KanaConverterRune {
+	for _, r := range out {
+		if r.Start == 0 {
+			r.Start = first.Start
+		} else {
+			r.Start = second.Start
+		}
+		if r.End == 1 {
+			r.End = first.End
+		} else {
+			r.End = second.End
+		}
+		dst = append(dst, r)
+	}
+	return dst
+}
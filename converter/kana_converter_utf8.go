@@ -0,0 +1,83 @@
+package converter
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// InvalidUTF8Policy selects how a KanaConverter handles input which is not
+// valid UTF-8.
+type InvalidUTF8Policy int
+
+const (
+	// ReplaceInvalidUTF8 replaces each invalid byte with U+FFFD.
+	ReplaceInvalidUTF8 InvalidUTF8Policy = iota
+	// RejectInvalidUTF8 fails the conversion with an *InvalidUTF8Error.
+	RejectInvalidUTF8
+	// PassInvalidUTF8 copies each invalid byte to the output untouched.
+	PassInvalidUTF8
+)
+
+// ParseInvalidUTF8Policy returns the policy named "replace", "error" or
+// "pass".
+func ParseInvalidUTF8Policy(s string) (InvalidUTF8Policy, error) {
+	switch s {
+	case "replace":
+		return ReplaceInvalidUTF8, nil
+	case "error":
+		return RejectInvalidUTF8, nil
+	case "pass":
+		return PassInvalidUTF8, nil
+	}
+	return 0, fmt.Errorf("unknown invalid UTF-8 policy %q: must be 'replace', 'error' or 'pass'", s)
+}
+
+func (p InvalidUTF8Policy) String() string {
+	switch p {
+	case ReplaceInvalidUTF8:
+		return "replace"
+	case RejectInvalidUTF8:
+		return "error"
+	case PassInvalidUTF8:
+		return "pass"
+	}
+	return fmt.Sprintf("InvalidUTF8Policy(%d)", int(p))
+}
+
+// InvalidUTF8Error is returned for invalid UTF-8 under RejectInvalidUTF8.
+type InvalidUTF8Error struct {
+	// Offset is the byte offset of the first invalid byte.
+	Offset int
+}
+
+func (e *InvalidUTF8Error) Error() string {
+	return fmt.Sprintf("invalid UTF-8 at byte offset %d", e.Offset)
+}
+
+func validateUTF8String(s string) error {
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			return &InvalidUTF8Error{Offset: i}
+		}
+		i += size
+	}
+	return nil
+}
+
+func validateUTF8(b []byte) error {
+	for i := 0; i < len(b); {
+		r, size := utf8.DecodeRune(b[i:])
+		if r == utf8.RuneError && size == 1 {
+			return &InvalidUTF8Error{Offset: i}
+		}
+		i += size
+	}
+	return nil
+}
+
+// isInvalidUTF8Rune reports whether r stands for an invalid byte of the input.
+// No stage converts U+FFFD, and a valid U+FFFD takes 3 bytes.
+func isInvalidUTF8Rune(r KanaConverterRune) bool {
+	return r.Rune == utf8.RuneError && !r.IsConverted && r.End-r.Start == 1
+}
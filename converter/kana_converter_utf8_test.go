@@ -0,0 +1,101 @@
+package converter_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ArmadaSuit/udf-go/converter"
+)
+
+func TestKanaConverterConvertWithPolicy(t *testing.T) {
+	type args struct {
+		in     string
+		policy converter.InvalidUTF8Policy
+	}
+	tests := []struct {
+		name       string
+		args       args
+		want       string
+		wantOffset int
+		wantErr    bool
+	}{
+		{
+			name: "valid: replace",
+			args: args{in: "ｶﾞ�", policy: converter.ReplaceInvalidUTF8},
+			want: "ガ�",
+		},
+		{
+			name: "valid: error",
+			args: args{in: "ｶﾞ�", policy: converter.RejectInvalidUTF8},
+			want: "ガ�",
+		},
+		{
+			name: "valid: pass",
+			args: args{in: "ｶﾞ�", policy: converter.PassInvalidUTF8},
+			want: "ガ�",
+		},
+		{
+			name: "latin1: replace",
+			args: args{in: "caf\xe9 ｶﾞ", policy: converter.ReplaceInvalidUTF8},
+			want: "caf� ガ",
+		},
+		{
+			name:       "latin1: error",
+			args:       args{in: "caf\xe9 ｶﾞ", policy: converter.RejectInvalidUTF8},
+			wantOffset: 3,
+			wantErr:    true,
+		},
+		{
+			name: "latin1: pass",
+			args: args{in: "caf\xe9 ｶﾞ", policy: converter.PassInvalidUTF8},
+			want: "caf\xe9 ガ",
+		},
+		{
+			name: "truncated sequence between hankaku katakana and voiced mark: pass",
+			args: args{in: "ｶ\xe3\x82ﾞ", policy: converter.PassInvalidUTF8},
+			want: "カ\xe3\x82゛",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+
+			t.Parallel()
+
+			c, err := converter.Compile("KV")
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := c.ConvertWithPolicy(tt.args.in, tt.args.policy)
+			gotBytes, errBytes := c.AppendConvertWithPolicy(nil, []byte(tt.args.in), tt.args.policy)
+			if (err != nil) != tt.wantErr || (errBytes != nil) != tt.wantErr {
+				t.Fatalf("ConvertWithPolicy() error = %v, %v, wantErr %v", err, errBytes, tt.wantErr)
+			}
+			if err != nil {
+				var e *converter.InvalidUTF8Error
+				if !errors.As(err, &e) || e.Offset != tt.wantOffset {
+					t.Errorf("ConvertWithPolicy() error = %v, want offset %v", err, tt.wantOffset)
+				}
+				return
+			}
+			if got != tt.want {
+				t.Errorf("%q is converted %q, want %q", tt.args.in, got, tt.want)
+			}
+			if string(gotBytes) != tt.want {
+				t.Errorf("%q is converted %q by AppendConvertWithPolicy, want %q", tt.args.in, gotBytes, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseInvalidUTF8Policy(t *testing.T) {
+	for _, p := range []converter.InvalidUTF8Policy{converter.ReplaceInvalidUTF8, converter.RejectInvalidUTF8, converter.PassInvalidUTF8} {
+		got, err := converter.ParseInvalidUTF8Policy(p.String())
+		if err != nil || got != p {
+			t.Errorf("ParseInvalidUTF8Policy(%q) = %v, %v, want %v", p.String(), got, err, p)
+		}
+	}
+	if _, err := converter.ParseInvalidUTF8Policy("ignore"); err == nil {
+		t.Errorf("ParseInvalidUTF8Policy(%q) error = nil, want error", "ignore")
+	}
+}
@@ -0,0 +1,117 @@
+package converter
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// variantKanjiTable maps variant kanji, such as itaiji and kyujitai, to their
+// common forms. Its header holds a version line, "# version: 1", which is
+// changed whenever the mappings are.
+//
+//go:embed data/itaiji.tsv
+var variantKanjiTable []byte
+
+// The names of the registered stages folding variant kanji and historical
+// kana.
+const (
+	variantKanjiStageName   = "variant_itaiji"
+	historicalKanaStageName = "variant_historical_kana"
+)
+
+// VariantKanjiTableVersion is the version of the embedded table of the
+// variant_itaiji stage, so that folded values stored by an older table can be
+// told apart.
+var VariantKanjiTableVersion string
+
+// variantKanji is the table of the variant_itaiji stage, together with the
+// CJK compatibility ideographs mapped to their canonical equivalents.
+var variantKanji *KanaConverterMapping
+
+// the variant stages are registered to be used in modes, such as
+// "KV+variant_itaiji+variant_historical_kana"
+func init() {
+	m, err := ParseKanaConverterMappingTSV(variantKanjiStageName, bytes.NewReader(variantKanjiTable))
+	if err != nil {
+		panic(err)
+	}
+	s := bufio.NewScanner(bytes.NewReader(variantKanjiTable))
+	for s.Scan() {
+		if v := strings.TrimPrefix(s.Text(), "# version:"); v != s.Text() {
+			VariantKanjiTableVersion = strings.TrimSpace(v)
+			break
+		}
+	}
+	if VariantKanjiTableVersion == "" {
+		panic(fmt.Sprintf("mapping %q: version line missing", variantKanjiStageName))
+	}
+	for _, block := range [][2]rune{{0xf900, 0xfaff}, {0x2f800, 0x2fa1f}} {
+		for r := block[0]; r <= block[1]; r++ {
+			if _, ok := m.mapping[r]; ok {
+				continue
+			}
+			c, n := utf8.DecodeRuneInString(norm.NFC.String(string(r)))
+			if c == r || n == 0 {
+				continue
+			}
+			if to, ok := m.mapping[c]; ok {
+				m.mapping[r] = to
+			} else {
+				m.mapping[r] = []rune{c}
+			}
+		}
+	}
+	variantKanji = m
+
+	for _, d := range []KanaConverterStageDefinition{{
+		Name: variantKanjiStageName,
+		NewStage: func() KanaConverterStage {
+			return variantKanji
+		},
+	}, {
+		Name: historicalKanaStageName,
+		NewStage: func() KanaConverterStage {
+			return KanaConverterFunc(foldHistoricalKana)
+		},
+	}} {
+		if err := RegisterKanaConverterStage(d); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// FoldVariantKanji folds variant kanji to their common forms, such as 髙 to 高,
+// 﨑 to 崎, 邊 and 邉 to 辺 and 櫻 to 桜, by the embedded table of the version
+// VariantKanjiTableVersion. The CJK compatibility ideographs are folded to
+// their canonical equivalents as well.
+func FoldVariantKanji(in <-chan KanaConverterRune) <-chan KanaConverterRune {
+	return variantKanji.Convert(in)
+}
+
+// FoldHistoricalKana folds the kana no longer in use to the ones read alike,
+// ゐ to い, ゑ to え, ヰ to イ, ヱ to エ, ヸ to ヴィ and ヹ to ヴェ.
+func FoldHistoricalKana(in <-chan KanaConverterRune) <-chan KanaConverterRune {
+	return convertForKanaConverter(KanaConverterFunc(foldHistoricalKana), in)
+}
+
+var historicalKana = map[rune][]rune{
+	'ゐ': []rune("い"), 'ゑ': []rune("え"), 'ヰ': []rune("イ"), 'ヱ': []rune("エ"),
+	'ヸ': []rune("ヴィ"), 'ヹ': []rune("ヴェ"),
+}
+
+func foldHistoricalKana(dst []KanaConverterRune, r KanaConverterRune) []KanaConverterRune {
+	to, ok := historicalKana[r.Rune]
+	if !ok {
+		return append(dst, r)
+	}
+	for _, c := range to {
+		dst = append(dst, r.convert(c))
+	}
+	return dst
+}
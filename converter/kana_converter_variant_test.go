@@ -0,0 +1,74 @@
+package converter_test
+
+import (
+	"testing"
+
+	"github.com/ArmadaSuit/udf-go/converter"
+)
+
+func TestFoldVariants(t *testing.T) {
+	type args struct {
+		in   string
+		mode string
+	}
+	tests := []struct {
+		name string
+		args args
+		want string
+	}{
+		{
+			name: "itaiji",
+			args: args{in: "髙橋、﨑山、山嵜、渡邊、渡邉、渡辺、櫻井、𠮷田", mode: "+variant_itaiji"},
+			want: "高橋、崎山、山崎、渡辺、渡辺、渡辺、桜井、吉田",
+		},
+		{
+			name: "kyujitai",
+			args: args{in: "國學院、舊字體、齋藤、澤田、廣瀨", mode: "+variant_itaiji"},
+			want: "国学院、旧字体、斎藤、沢田、広瀬",
+		},
+		{
+			name: "compatibility ideographs",
+			args: args{in: "\uf9dc\ufa19\ufa5b", mode: "+variant_itaiji"},
+			want: "隆神者",
+		},
+		{
+			name: "historical kana",
+			args: args{in: "ゐゑヰヱヸヹ、ゐ之助", mode: "+variant_historical_kana"},
+			want: "いえイエヴィヴェ、い之助",
+		},
+		{
+			name: "historical kana with other stages",
+			args: args{in: "ﾜｲﾝ、ヱビス、ゑびす、邊", mode: "KVC+variant_itaiji+variant_historical_kana"},
+			want: "ワイン、エビス、エビス、辺",
+		},
+		{
+			name: "others are kept",
+			args: args{in: "高橋、あいう、ABC", mode: "+variant_itaiji+variant_historical_kana"},
+			want: "高橋、あいう、ABC",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+
+			t.Parallel()
+
+			c, err := converter.Compile(tt.args.mode)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := c.Convert(tt.args.in); got != tt.want {
+				t.Errorf("%v is converted %v, want %v", tt.args.in, got, tt.want)
+			}
+			if got, _ := convertByChannel(tt.args.in, tt.args.mode); got != tt.want {
+				t.Errorf("%v is converted %v by channels, want %v", tt.args.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVariantKanjiTableVersion(t *testing.T) {
+	if converter.VariantKanjiTableVersion != "1" {
+		t.Errorf("the table version is %v, want 1", converter.VariantKanjiTableVersion)
+	}
+}
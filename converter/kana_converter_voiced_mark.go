@@ -0,0 +1,110 @@
+package converter
+
+// voicedMarkCompositions maps a zenkaku kana to its voiced and semi-voiced
+// forms, or 0 for the form which does not exist.
+var voicedMarkCompositions = func() map[rune][2]rune {
+	m := make(map[rune][2]rune)
+	for _, r := range "かきくけこさしすせそたちつてと" {
+		m[r] = [2]rune{r + 1, 0}
+	}
+	for _, r := range "はひふへほ" {
+		m[r] = [2]rune{r + 1, r + 2}
+	}
+	m['う'] = [2]rune{'ゔ', 0}
+	m['ゝ'] = [2]rune{'ゞ', 0}
+	// katakana are at the same distance from hiragana
+	katakana := make(map[rune][2]rune, len(m))
+	for r, c := range m {
+		k := [2]rune{c[0] + 'ァ' - 'ぁ', 0}
+		if c[1] != 0 {
+			k[1] = c[1] + 'ァ' - 'ぁ'
+		}
+		katakana[r+'ァ'-'ぁ'] = k
+	}
+	for r, c := range katakana {
+		m[r] = c
+	}
+	for _, r := range "ワヰヱヲ" {
+		m[r] = [2]rune{r + 'ヷ' - 'ワ', 0}
+	}
+	return m
+}()
+
+// voicedMarkDecompositions maps a voiced or semi-voiced zenkaku kana to its
+// base and combining mark.
+var voicedMarkDecompositions = func() map[rune][2]rune {
+	m := make(map[rune][2]rune)
+	for r, c := range voicedMarkCompositions {
+		m[c[0]] = [2]rune{r, '\u3099'}
+		if c[1] != 0 {
+			m[c[1]] = [2]rune{r, '\u309a'}
+		}
+	}
+	return m
+}()
+
+// voicedMarkIndex returns 0 for a voiced mark, 1 for a semi-voiced mark and -1
+// for the others. Combining, spacing and hankaku marks are treated alike.
+func voicedMarkIndex(r rune) int {
+	switch r {
+	case '\u3099', '゛', 'ﾞ':
+		return 0
+	case '\u309a', '゜', 'ﾟ':
+		return 1
+	}
+	return -1
+}
+
+// ComposeVoicedMarks composes zenkaku hiragana and katakana followed by a
+// voiced or semi-voiced mark, such as か + U+3099, か + ゛ and か + ﾞ, into
+// one rune.
+func ComposeVoicedMarks(in <-chan KanaConverterRune) <-chan KanaConverterRune {
+	return convertForKanaConverter(&composeVoicedMarksStage{}, in)
+}
+
+// composeVoicedMarksStage holds a kana until the next rune tells whether it is
+// composed. Runes converted by earlier stages are composed too.
+type composeVoicedMarksStage struct {
+	before *KanaConverterRune
+}
+
+func (s *composeVoicedMarksStage) Push(dst []KanaConverterRune, r KanaConverterRune) []KanaConverterRune {
+	if s.before != nil {
+		if i := voicedMarkIndex(r.Rune); i >= 0 {
+			if c := voicedMarkCompositions[s.before.Rune][i]; c != 0 {
+				dst = append(dst, s.before.compose(r, c))
+				s.before = nil
+				return dst
+			}
+		}
+		dst = s.Flush(dst)
+	}
+	if _, ok := voicedMarkCompositions[r.Rune]; ok {
+		r := r
+		s.before = &r
+		return dst
+	}
+	return append(dst, r)
+}
+
+func (s *composeVoicedMarksStage) Flush(dst []KanaConverterRune) []KanaConverterRune {
+	if s.before != nil {
+		dst = append(dst, *s.before)
+		s.before = nil
+	}
+	return dst
+}
+
+// DecomposeVoicedMarks decomposes voiced and semi-voiced zenkaku hiragana and
+// katakana into the base and a combining mark, such as が into か + U+3099.
+func DecomposeVoicedMarks(in <-chan KanaConverterRune) <-chan KanaConverterRune {
+	return convertForKanaConverter(KanaConverterFunc(decomposeVoicedMarks), in)
+}
+
+func decomposeVoicedMarks(dst []KanaConverterRune, r KanaConverterRune) []KanaConverterRune {
+	d, ok := voicedMarkDecompositions[r.Rune]
+	if !ok {
+		return append(dst, r)
+	}
+	return append(dst, r.convert(d[0]), r.convert(d[1]))
+}
@@ -0,0 +1,110 @@
+package converter_test
+
+import (
+	"testing"
+
+	"github.com/ArmadaSuit/udf-go/converter"
+)
+
+func TestComposeVoicedMarks(t *testing.T) {
+	type args struct {
+		in   string
+		mode string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "combining marks",
+			args: args{in: "がパゔヷゞ", mode: "M"},
+			want: "がパゔヷゞ",
+		},
+		{
+			name: "spacing marks",
+			args: args{in: "か゛ハ゜ヲ゛ヽ゛", mode: "M"},
+			want: "がパヺヾ",
+		},
+		{
+			name: "hankaku marks",
+			args: args{in: "かﾞはﾟ", mode: "M"},
+			want: "がぱ",
+		},
+		{
+			name: "marks without a composed form are kept",
+			args: args{in: "か゜あ゛゙ん", mode: "M"},
+			want: "か゜あ゛゙ん",
+		},
+		{
+			name: "hankaku katakana is not composed",
+			args: args{in: "ｶﾞ", mode: "M"},
+			want: "ｶﾞ",
+		},
+		{
+			name: "after hankaku katakana to zenkaku katakana",
+			args: args{in: "ｶﾞﾊ゚か", mode: "KVM"},
+			want: "ガパか",
+		},
+		{
+			name: "marks left by hankaku katakana to hiragana without V",
+			args: args{in: "ｶﾞﾊﾟ", mode: "HM"},
+			want: "がぱ",
+		},
+		{
+			name: "after katakana to hiragana",
+			args: args{in: "ガ", mode: "cM"},
+			want: "が",
+		},
+		{
+			name: "decompose",
+			args: args{in: "がパゔヸゞヾか", mode: "m"},
+			want: "がパゔヸゞヾか",
+		},
+		{
+			name: "decompose after hankaku katakana to zenkaku katakana",
+			args: args{in: "ｶﾞﾎﾟ", mode: "KVm"},
+			want: "ガポ",
+		},
+		{
+			name:    "compose and decompose",
+			args:    args{mode: "Mm"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+
+			t.Parallel()
+
+			c, err := converter.Compile(tt.args.mode)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Compile() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+			if got := c.Convert(tt.args.in); got != tt.want {
+				t.Errorf("%v is converted %v, want %v", tt.args.in, got, tt.want)
+			}
+			if got, _ := convertByChannel(tt.args.in, tt.args.mode); got != tt.want {
+				t.Errorf("%v is converted %v by channels, want %v", tt.args.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestComposeVoicedMarksOffsets(t *testing.T) {
+	c, err := converter.Compile("M")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, offsets := c.ConvertWithOffsets("あが")
+	want := converter.KanaConverterSpan{Start: 3, End: 9}
+	if got := offsets[1].Input; got != want {
+		t.Errorf("input of が = %v, want %v", got, want)
+	}
+}
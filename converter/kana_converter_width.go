@@ -0,0 +1,93 @@
+package converter
+
+import (
+	"errors"
+	"unicode/utf8"
+
+	"golang.org/x/text/width"
+)
+
+// RuneWidth returns the display width of r as mb_strwidth of PHP counts it,
+// 2 for the East Asian wide and fullwidth characters, such as kanji, zenkaku
+// kana and zenkaku Latin letters, and 1 for the others, such as hankaku kana.
+// Invalid UTF-8 decoded as U+FFFD is 1 as well.
+func RuneWidth(r rune) int {
+	switch width.LookupRune(r).Kind() {
+	case width.EastAsianWide, width.EastAsianFullwidth:
+		return 2
+	}
+	return 1
+}
+
+// StringWidth returns the display width of s as mb_strwidth of PHP does,
+// such as 4 for ｱｲｳｴ and 8 for アイウエ.
+func StringWidth(s string) int {
+	w := 0
+	for _, r := range s {
+		w += RuneWidth(r)
+	}
+	return w
+}
+
+var (
+	errTrimWidthStart = errors.New("start is out of range")
+	errTrimWidthWidth = errors.New("width is out of range")
+)
+
+// TrimWidth truncates s to width as mb_strimwidth of PHP does. The result
+// starts at the start-th rune, counted from the end if negative, and is
+// returned as is when it fits in width. Otherwise it is cut so that it fits
+// with trimMarker appended, and trimMarker alone is returned if it does not
+// fit itself. A negative width is counted from the end of s. A voiced or
+// semi-voiced sound mark is not cut from the kana before it, so ｶﾞ is
+// dropped as a whole.
+func TrimWidth(s string, start, width int, trimMarker string) (string, error) {
+	n := utf8.RuneCountInString(s)
+	if start < 0 {
+		start += n
+	}
+	if start < 0 || start > n {
+		return "", errTrimWidthStart
+	}
+	for ; start > 0; start-- {
+		_, size := utf8.DecodeRuneInString(s)
+		s = s[size:]
+	}
+
+	w := StringWidth(s)
+	if width < 0 {
+		width += w
+		if width < 0 {
+			return "", errTrimWidthWidth
+		}
+	}
+	if w <= width {
+		return s, nil
+	}
+	rest := width - StringWidth(trimMarker)
+	if rest < 0 {
+		return trimMarker, nil
+	}
+
+	cut := 0
+	for i, r := range s {
+		if rest < RuneWidth(r) {
+			cut = i
+			break
+		}
+		rest -= RuneWidth(r)
+	}
+	// a sound mark which does not fit drops the kana before it too
+	for cut > 0 {
+		if r, _ := utf8.DecodeRuneInString(s[cut:]); !isTrimWidthSoundMark(r) {
+			break
+		}
+		_, size := utf8.DecodeLastRuneInString(s[:cut])
+		cut -= size
+	}
+	return s[:cut] + trimMarker, nil
+}
+
+func isTrimWidthSoundMark(r rune) bool {
+	return r == 'ﾞ' || r == 'ﾟ' || r == '\u3099' || r == '\u309a'
+}
@@ -0,0 +1,87 @@
+package converter_test
+
+import (
+	"testing"
+
+	"github.com/ArmadaSuit/udf-go/converter"
+)
+
+func TestStringWidth(t *testing.T) {
+	type args struct {
+		s string
+	}
+	tests := []struct {
+		name string
+		args args
+		want int
+	}{
+		{name: "empty", args: args{s: ""}, want: 0},
+		{name: "hankaku", args: args{s: "abc 123"}, want: 7},
+		{name: "hankaku katakana", args: args{s: "ｱｲｳｴｵ"}, want: 5},
+		{name: "hankaku katakana with sound marks", args: args{s: "ｶﾞﾊﾟ"}, want: 4},
+		{name: "zenkaku", args: args{s: "アイウ漢字ＡＢ１　"}, want: 18},
+		{name: "mixed", args: args{s: "ﾃｽﾄテスト test"}, want: 14},
+		{name: "invalid utf-8", args: args{s: "a\xffあ"}, want: 4},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+
+			t.Parallel()
+
+			if got := converter.StringWidth(tt.args.s); got != tt.want {
+				t.Errorf("the width of %v is %v, want %v", tt.args.s, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrimWidth(t *testing.T) {
+	type args struct {
+		s          string
+		start      int
+		width      int
+		trimMarker string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    string
+		wantErr bool
+	}{
+		{name: "fits", args: args{s: "Hello", start: 0, width: 5, trimMarker: "..."}, want: "Hello"},
+		{name: "trimmed", args: args{s: "Hello World", start: 0, width: 10, trimMarker: "..."}, want: "Hello W..."},
+		{name: "no marker", args: args{s: "Hello World", start: 0, width: 5}, want: "Hello"},
+		{name: "start", args: args{s: "Hello World", start: 6, width: 4, trimMarker: "."}, want: "Wor."},
+		{name: "negative start", args: args{s: "Hello World", start: -5, width: 5}, want: "World"},
+		{name: "negative width", args: args{s: "Hello World", start: 0, width: -3, trimMarker: "..."}, want: "Hello..."},
+		{name: "zenkaku", args: args{s: "あいうえお", start: 0, width: 7, trimMarker: "…"}, want: "あいう…"},
+		{name: "zenkaku odd width", args: args{s: "あいうえお", start: 0, width: 6}, want: "あいう"},
+		{name: "zenkaku not cut in half", args: args{s: "あいうえお", start: 0, width: 5}, want: "あい"},
+		{name: "hankaku katakana", args: args{s: "ﾃｽﾄﾃﾞｰﾀ", start: 0, width: 4, trimMarker: ".."}, want: "ﾃｽ.."},
+		{name: "sound mark not cut", args: args{s: "ﾃｽﾄﾃﾞｰﾀ", start: 0, width: 5, trimMarker: "."}, want: "ﾃｽﾄ."},
+		{name: "sound mark fits", args: args{s: "ﾃｽﾄﾃﾞｰﾀ", start: 0, width: 6, trimMarker: "."}, want: "ﾃｽﾄﾃﾞ."},
+		{name: "semi-voiced sound mark", args: args{s: "ﾊﾟﾋﾟﾌﾟ", start: 0, width: 3}, want: "ﾊﾟ"},
+		{name: "combining sound mark", args: args{s: "か\u3099き", start: 0, width: 3}, want: ""},
+		{name: "marker does not fit", args: args{s: "Hello", start: 0, width: 2, trimMarker: "..."}, want: "..."},
+		{name: "start at the end", args: args{s: "Hello", start: 5, width: 2}, want: ""},
+		{name: "start out of range", args: args{s: "Hello", start: 6, width: 2}, wantErr: true},
+		{name: "negative start out of range", args: args{s: "Hello", start: -6, width: 2}, wantErr: true},
+		{name: "negative width out of range", args: args{s: "Hello", start: 0, width: -6}, wantErr: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+
+			t.Parallel()
+
+			got, err := converter.TrimWidth(tt.args.s, tt.args.start, tt.args.width, tt.args.trimMarker)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("TrimWidth() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("%v is trimmed %v, want %v", tt.args.s, got, tt.want)
+			}
+		})
+	}
+}
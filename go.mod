@@ -1,3 +1,5 @@
 module github.com/ArmadaSuit/udf-go
 
 go 1.18
+
+require golang.org/x/text v0.21.0
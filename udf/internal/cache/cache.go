@@ -0,0 +1,117 @@
+// Package cache caches the converters compiled for the modes given to the
+// UDFs, so a mode is parsed once and shared between rows and server threads.
+package cache
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/ArmadaSuit/udf-go/converter"
+)
+
+// Limit is the number of the converters kept by a Cache. A mode given by a
+// column rather than a constant may differ on every row, so the cache must not
+// grow with them.
+const Limit = 64
+
+// Cache keeps up to Limit converters compiled by its function.
+type Cache struct {
+	compile    func(key string) (*converter.KanaConverter, error)
+	mu         sync.RWMutex
+	converters map[string]*converter.KanaConverter
+}
+
+// New returns a Cache of the converters compiled by compile.
+func New(compile func(key string) (*converter.KanaConverter, error)) *Cache {
+	return &Cache{compile: compile, converters: make(map[string]*converter.KanaConverter)}
+}
+
+// Get returns the converter of key, compiling it unless it is cached. When
+// the cache is full, one of the converters cached is dropped for it.
+func (c *Cache) Get(key string) (*converter.KanaConverter, error) {
+	c.mu.RLock()
+	kc, ok := c.converters[key]
+	c.mu.RUnlock()
+	if ok {
+		return kc, nil
+	}
+
+	kc, err := c.compile(key)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if cached, ok := c.converters[key]; ok {
+		return cached, nil
+	}
+	if len(c.converters) >= Limit {
+		for k := range c.converters {
+			delete(c.converters, k)
+			break
+		}
+	}
+	c.converters[key] = kc
+	return kc, nil
+}
+
+// Len returns the number of the converters cached.
+func (c *Cache) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.converters)
+}
+
+// mappingsErr is the error loading the mapping tables, reported when a mode
+// fails to compile.
+var mappingsErr error
+
+// the mapping tables in the directory named by UDF_CONVERT_KANA_MAPPING_DIR
+// are loaded when the library is loaded, to be referred as "KV+house"
+func init() {
+	if dir := os.Getenv("UDF_CONVERT_KANA_MAPPING_DIR"); dir != "" {
+		mappingsErr = converter.LoadKanaConverterMappings(dir)
+	}
+}
+
+var kana = New(func(mode string) (*converter.KanaConverter, error) {
+	c, err := converter.Compile(mode)
+	if err != nil && mappingsErr != nil {
+		return nil, fmt.Errorf("%v (failed to load mapping tables: %v)", err, mappingsErr)
+	}
+	return c, err
+})
+
+// Kana returns the converter compiled from mode, such as "KV+house".
+func Kana(mode string) (*converter.KanaConverter, error) {
+	return kana.Get(mode)
+}
+
+var caseConversion = New(func(name string) (*converter.KanaConverter, error) {
+	mode, err := converter.ParseCaseMode(name)
+	if err != nil {
+		return nil, err
+	}
+	return converter.CompileCaseConversion(mode)
+})
+
+// Case returns the converter of the case mode named name, such as "upper".
+func Case(name string) (*converter.KanaConverter, error) {
+	return caseConversion.Get(name)
+}
+
+var romaji = New(func(name string) (*converter.KanaConverter, error) {
+	style, err := converter.ParseRomajiStyle(name)
+	if err != nil {
+		return nil, err
+	}
+	return converter.CompileKanaToRomaji(style)
+})
+
+// Romaji returns the converter of the romaji style named name, such as
+// "hepburn".
+func Romaji(name string) (*converter.KanaConverter, error) {
+	return romaji.Get(name)
+}
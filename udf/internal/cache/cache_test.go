@@ -0,0 +1,75 @@
+package cache_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ArmadaSuit/udf-go/converter"
+	"github.com/ArmadaSuit/udf-go/udf/internal/cache"
+)
+
+func TestCache(t *testing.T) {
+	compiled := 0
+	c := cache.New(func(key string) (*converter.KanaConverter, error) {
+		compiled++
+		return converter.Compile("KV")
+	})
+
+	first, err := c.Get("first")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := c.Get("first"); again != first || compiled != 1 {
+		t.Errorf("Get() compiled %v times for the same key, want 1", compiled)
+	}
+	for i := 0; i < cache.Limit*2; i++ {
+		if _, err := c.Get(fmt.Sprint(i)); err != nil {
+			t.Fatal(err)
+		}
+		if got := c.Len(); got > cache.Limit {
+			t.Fatalf("Len() = %v, want at most %v", got, cache.Limit)
+		}
+	}
+}
+
+func TestKana(t *testing.T) {
+	type args struct {
+		mode string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "mode",
+			args: args{mode: "KV"},
+			want: "ガ",
+		},
+		{
+			name:    "invalid mode",
+			args:    args{mode: "kK"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+
+			t.Parallel()
+
+			c, err := cache.Kana(tt.args.mode)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Kana() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+			if got := c.Convert("ｶﾞ"); got != tt.want {
+				t.Errorf("%v is converted %v, want %v", "ｶﾞ", got, tt.want)
+			}
+		})
+	}
+}
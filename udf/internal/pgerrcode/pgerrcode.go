@@ -0,0 +1,23 @@
+// Package pgerrcode picks the SQLSTATE codes which the PostgreSQL UDFs raise
+// their errors with.
+package pgerrcode
+
+import (
+	/*
+		#include <postgres.h>
+	*/
+	"C"
+	"errors"
+
+	"github.com/ArmadaSuit/udf-go/converter"
+)
+
+// Date returns the code of err parsing or formatting a date, which is either
+// out of range or in an invalid format. The caller converts it to C.int of its
+// own package.
+func Date(err error) int32 {
+	if errors.Is(err, converter.ErrJapaneseDateRange) {
+		return C.ERRCODE_DATETIME_VALUE_OUT_OF_RANGE
+	}
+	return C.ERRCODE_INVALID_DATETIME_FORMAT
+}
@@ -10,12 +10,13 @@ import (
 	"unsafe"
 
 	"github.com/ArmadaSuit/udf-go/converter"
+	"github.com/ArmadaSuit/udf-go/udf/internal/cache"
 )
 
 //export udf_convert_kana_init
 func udf_convert_kana_init(initid *C.UDF_INIT, args *C.UDF_ARGS, message *C.char) C.bool {
-	if args.arg_count != 2 {
-		m := C.CString("2 arguments expected")
+	if args.arg_count != 2 && args.arg_count != 3 {
+		m := C.CString("2 or 3 arguments expected")
 		defer C.free(unsafe.Pointer(m))
 		C.strcpy(message, m)
 		return C.bool(true)
@@ -23,42 +24,81 @@ func udf_convert_kana_init(initid *C.UDF_INIT, args *C.UDF_ARGS, message *C.char
 
 	argsTypes := unsafe.Slice(args.arg_type, args.arg_count)
 
-	if argsTypes[0] != C.STRING_RESULT || argsTypes[1] != C.STRING_RESULT {
-		m := C.CString("2 arguments must be string")
-		defer C.free(unsafe.Pointer(m))
-		C.strcpy(message, m)
-		return C.bool(true)
+	for _, t := range argsTypes {
+		if t != C.STRING_RESULT {
+			m := C.CString("all arguments must be string")
+			defer C.free(unsafe.Pointer(m))
+			C.strcpy(message, m)
+			return C.bool(true)
+		}
 	}
 
 	argsArgs := unsafe.Slice(args.args, args.arg_count)
+	argsLengths := unsafe.Slice(args.lengths, args.arg_count)
 
-	_, err := converter.NewKanaConverterOptions(C.GoString(argsArgs[1]))
-	if err != nil {
-		m := C.CString(err.Error())
-		defer C.free(unsafe.Pointer(m))
-		C.strcpy(message, m)
-		return C.bool(true)
+	if argsArgs[1] != nil {
+		_, err := cache.Kana(C.GoStringN(argsArgs[1], C.int(argsLengths[1])))
+		if err != nil {
+			m := C.CString(err.Error())
+			defer C.free(unsafe.Pointer(m))
+			C.strcpy(message, m)
+			return C.bool(true)
+		}
+	}
+
+	if args.arg_count == 3 && argsArgs[2] != nil {
+		_, err := converter.ParseInvalidUTF8Policy(C.GoStringN(argsArgs[2], C.int(argsLengths[2])))
+		if err != nil {
+			m := C.CString(err.Error())
+			defer C.free(unsafe.Pointer(m))
+			C.strcpy(message, m)
+			return C.bool(true)
+		}
 	}
 
+	initid.maybe_null = C.bool(true)
+	initid.ptr = nil
+
 	return C.bool(false)
 }
 
+//export udf_convert_kana_deinit
+func udf_convert_kana_deinit(initid *C.UDF_INIT) {
+	C.free(unsafe.Pointer(initid.ptr))
+}
+
 //export udf_convert_kana
 func udf_convert_kana(initid *C.UDF_INIT, args *C.UDF_ARGS, result *C.char, length *C.ulong, isNull *C.char, err *C.char) *C.char {
 	argsArgs := unsafe.Slice(args.args, args.arg_count)
-	converters, _ := converter.NewKanaConverters(C.GoString(argsArgs[1]))
-	s := C.GoString(argsArgs[0])
-	in := converter.GenerateForKanaConverter(s)
-	for _, c := range converters {
-		in = c(in)
+	argsLengths := unsafe.Slice(args.lengths, args.arg_count)
+
+	c, e := cache.Kana(C.GoStringN(argsArgs[1], C.int(argsLengths[1])))
+	if e != nil {
+		*err = 1
+		return nil
 	}
-	str := converter.StringForKanaConverter(in)
-	cstr := C.CString(str)
-	defer C.free(unsafe.Pointer(cstr))
-	C.strcpy(result, cstr)
-	*length = C.ulong(len(str))
+	policy := converter.ReplaceInvalidUTF8
+	if args.arg_count == 3 {
+		policy, e = converter.ParseInvalidUTF8Policy(C.GoStringN(argsArgs[2], C.int(argsLengths[2])))
+		if e != nil {
+			*err = 1
+			return nil
+		}
+	}
+
+	b, e := c.AppendConvertWithPolicy(nil, C.GoBytes(unsafe.Pointer(argsArgs[0]), C.int(argsLengths[0])), policy)
+	if e != nil {
+		// only this row is NULL, whereas err would make the following rows NULL too
+		*isNull = 1
+		return nil
+	}
+
+	// the result may be longer than the buffer given by the server
+	C.free(unsafe.Pointer(initid.ptr))
+	initid.ptr = (*C.char)(C.CBytes(b))
+	*length = C.ulong(len(b))
 
-	return result
+	return initid.ptr
 }
 
 func main() {
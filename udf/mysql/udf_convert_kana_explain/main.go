@@ -0,0 +1,92 @@
+package main
+
+import (
+	/*
+		#include <stdlib.h>
+		#include <string.h>
+		#include <mysql.h>
+	*/
+	"C"
+	"encoding/json"
+	"unsafe"
+
+	"github.com/ArmadaSuit/udf-go/converter"
+	"github.com/ArmadaSuit/udf-go/udf/internal/cache"
+)
+
+//export udf_convert_kana_explain_init
+func udf_convert_kana_explain_init(initid *C.UDF_INIT, args *C.UDF_ARGS, message *C.char) C.bool {
+	if args.arg_count != 2 {
+		m := C.CString("2 arguments expected")
+		defer C.free(unsafe.Pointer(m))
+		C.strcpy(message, m)
+		return C.bool(true)
+	}
+
+	argsTypes := unsafe.Slice(args.arg_type, args.arg_count)
+
+	if argsTypes[0] != C.STRING_RESULT || argsTypes[1] != C.STRING_RESULT {
+		m := C.CString("2 arguments must be string")
+		defer C.free(unsafe.Pointer(m))
+		C.strcpy(message, m)
+		return C.bool(true)
+	}
+
+	argsArgs := unsafe.Slice(args.args, args.arg_count)
+	argsLengths := unsafe.Slice(args.lengths, args.arg_count)
+
+	if argsArgs[1] != nil {
+		_, err := cache.Kana(C.GoStringN(argsArgs[1], C.int(argsLengths[1])))
+		if err != nil {
+			m := C.CString(err.Error())
+			defer C.free(unsafe.Pointer(m))
+			C.strcpy(message, m)
+			return C.bool(true)
+		}
+	}
+
+	initid.maybe_null = C.bool(true)
+	initid.ptr = nil
+
+	return C.bool(false)
+}
+
+//export udf_convert_kana_explain_deinit
+func udf_convert_kana_explain_deinit(initid *C.UDF_INIT) {
+	C.free(unsafe.Pointer(initid.ptr))
+}
+
+//export udf_convert_kana_explain
+func udf_convert_kana_explain(initid *C.UDF_INIT, args *C.UDF_ARGS, result *C.char, length *C.ulong, isNull *C.char, err *C.char) *C.char {
+	argsArgs := unsafe.Slice(args.args, args.arg_count)
+	argsLengths := unsafe.Slice(args.lengths, args.arg_count)
+	if argsArgs[0] == nil || argsArgs[1] == nil {
+		*isNull = 1
+		return nil
+	}
+
+	c, e := cache.Kana(C.GoStringN(argsArgs[1], C.int(argsLengths[1])))
+	if e != nil {
+		*err = 1
+		return nil
+	}
+	_, traces := c.Explain(C.GoStringN(argsArgs[0], C.int(argsLengths[0])))
+	if traces == nil {
+		traces = []converter.KanaConverterTrace{}
+	}
+	b, e := json.Marshal(traces)
+	if e != nil {
+		*err = 1
+		return nil
+	}
+
+	// the result may be longer than the buffer given by the server
+	C.free(unsafe.Pointer(initid.ptr))
+	initid.ptr = (*C.char)(C.CBytes(b))
+	*length = C.ulong(len(b))
+
+	return initid.ptr
+}
+
+func main() {
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	/*
+		#include <stdlib.h>
+		#include <string.h>
+		#include <mysql.h>
+	*/
+	"C"
+	"unsafe"
+
+	"github.com/ArmadaSuit/udf-go/converter"
+)
+
+//export udf_fold_variants_init
+func udf_fold_variants_init(initid *C.UDF_INIT, args *C.UDF_ARGS, message *C.char) C.bool {
+	if args.arg_count != 1 {
+		m := C.CString("1 argument expected")
+		defer C.free(unsafe.Pointer(m))
+		C.strcpy(message, m)
+		return C.bool(true)
+	}
+
+	argsTypes := unsafe.Slice(args.arg_type, args.arg_count)
+
+	if argsTypes[0] != C.STRING_RESULT {
+		m := C.CString("argument must be string")
+		defer C.free(unsafe.Pointer(m))
+		C.strcpy(message, m)
+		return C.bool(true)
+	}
+
+	if foldVariantsErr != nil {
+		m := C.CString(foldVariantsErr.Error())
+		defer C.free(unsafe.Pointer(m))
+		C.strcpy(message, m)
+		return C.bool(true)
+	}
+
+	initid.maybe_null = C.bool(true)
+	initid.ptr = nil
+
+	return C.bool(false)
+}
+
+//export udf_fold_variants_deinit
+func udf_fold_variants_deinit(initid *C.UDF_INIT) {
+	C.free(unsafe.Pointer(initid.ptr))
+}
+
+//export udf_fold_variants
+func udf_fold_variants(initid *C.UDF_INIT, args *C.UDF_ARGS, result *C.char, length *C.ulong, isNull *C.char, err *C.char) *C.char {
+	argsArgs := unsafe.Slice(args.args, args.arg_count)
+	argsLengths := unsafe.Slice(args.lengths, args.arg_count)
+	if argsArgs[0] == nil {
+		*isNull = 1
+		return nil
+	}
+
+	b := foldVariants.ConvertBytes(C.GoBytes(unsafe.Pointer(argsArgs[0]), C.int(argsLengths[0])))
+
+	// the result may be longer than the buffer given by the server
+	C.free(unsafe.Pointer(initid.ptr))
+	initid.ptr = (*C.char)(C.CBytes(b))
+	*length = C.ulong(len(b))
+
+	return initid.ptr
+}
+
+// foldVariants is compiled once when the library is loaded.
+var foldVariants, foldVariantsErr = converter.Compile("+variant_itaiji+variant_historical_kana")
+
+func main() {
+}
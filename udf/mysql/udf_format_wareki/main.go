@@ -0,0 +1,103 @@
+package main
+
+import (
+	/*
+		#include <stdlib.h>
+		#include <string.h>
+		#include <mysql.h>
+	*/
+	"C"
+	"unsafe"
+
+	"github.com/ArmadaSuit/udf-go/converter"
+)
+
+//export udf_format_wareki_init
+func udf_format_wareki_init(initid *C.UDF_INIT, args *C.UDF_ARGS, message *C.char) C.bool {
+	if args.arg_count != 1 && args.arg_count != 2 {
+		m := C.CString("1 or 2 arguments expected")
+		defer C.free(unsafe.Pointer(m))
+		C.strcpy(message, m)
+		return C.bool(true)
+	}
+
+	argsTypes := unsafe.Slice(args.arg_type, args.arg_count)
+
+	for _, t := range argsTypes {
+		if t != C.STRING_RESULT {
+			m := C.CString("all arguments must be string")
+			defer C.free(unsafe.Pointer(m))
+			C.strcpy(message, m)
+			return C.bool(true)
+		}
+	}
+
+	argsArgs := unsafe.Slice(args.args, args.arg_count)
+	argsLengths := unsafe.Slice(args.lengths, args.arg_count)
+
+	if args.arg_count == 2 && argsArgs[1] != nil {
+		_, err := converter.ParseWarekiStyle(C.GoStringN(argsArgs[1], C.int(argsLengths[1])))
+		if err != nil {
+			m := C.CString(err.Error())
+			defer C.free(unsafe.Pointer(m))
+			C.strcpy(message, m)
+			return C.bool(true)
+		}
+	}
+
+	initid.maybe_null = C.bool(true)
+	initid.ptr = nil
+
+	return C.bool(false)
+}
+
+//export udf_format_wareki_deinit
+func udf_format_wareki_deinit(initid *C.UDF_INIT) {
+	C.free(unsafe.Pointer(initid.ptr))
+}
+
+//export udf_format_wareki
+func udf_format_wareki(initid *C.UDF_INIT, args *C.UDF_ARGS, result *C.char, length *C.ulong, isNull *C.char, err *C.char) *C.char {
+	argsArgs := unsafe.Slice(args.args, args.arg_count)
+	argsLengths := unsafe.Slice(args.lengths, args.arg_count)
+	for _, a := range argsArgs {
+		if a == nil {
+			*isNull = 1
+			return nil
+		}
+	}
+
+	style := converter.WarekiLong
+	if args.arg_count == 2 {
+		var e error
+		style, e = converter.ParseWarekiStyle(C.GoStringN(argsArgs[1], C.int(argsLengths[1])))
+		if e != nil {
+			*err = 1
+			return nil
+		}
+	}
+
+	// an invalid date makes only this row NULL, whereas err would make the
+	// following rows NULL too
+	t, e := converter.ParseJapaneseDate(C.GoStringN(argsArgs[0], C.int(argsLengths[0])))
+	if e != nil {
+		*isNull = 1
+		return nil
+	}
+	s, e := converter.FormatWareki(t, style)
+	if e != nil {
+		*isNull = 1
+		return nil
+	}
+
+	b := []byte(s)
+
+	C.free(unsafe.Pointer(initid.ptr))
+	initid.ptr = (*C.char)(C.CBytes(b))
+	*length = C.ulong(len(b))
+
+	return initid.ptr
+}
+
+func main() {
+}
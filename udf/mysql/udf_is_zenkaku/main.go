@@ -0,0 +1,54 @@
+package main
+
+import (
+	/*
+		#include <stdlib.h>
+		#include <string.h>
+		#include <mysql.h>
+	*/
+	"C"
+	"unsafe"
+
+	"github.com/ArmadaSuit/udf-go/converter"
+)
+
+//export udf_is_zenkaku_init
+func udf_is_zenkaku_init(initid *C.UDF_INIT, args *C.UDF_ARGS, message *C.char) C.bool {
+	if args.arg_count != 1 {
+		m := C.CString("1 argument expected")
+		defer C.free(unsafe.Pointer(m))
+		C.strcpy(message, m)
+		return C.bool(true)
+	}
+
+	argsTypes := unsafe.Slice(args.arg_type, args.arg_count)
+
+	if argsTypes[0] != C.STRING_RESULT {
+		m := C.CString("argument must be string")
+		defer C.free(unsafe.Pointer(m))
+		C.strcpy(message, m)
+		return C.bool(true)
+	}
+
+	initid.maybe_null = C.bool(true)
+
+	return C.bool(false)
+}
+
+//export udf_is_zenkaku
+func udf_is_zenkaku(initid *C.UDF_INIT, args *C.UDF_ARGS, isNull *C.char, err *C.char) C.longlong {
+	argsArgs := unsafe.Slice(args.args, args.arg_count)
+	argsLengths := unsafe.Slice(args.lengths, args.arg_count)
+	if argsArgs[0] == nil {
+		*isNull = 1
+		return 0
+	}
+
+	if converter.CheckKanaClass(C.GoStringN(argsArgs[0], C.int(argsLengths[0])), converter.KanaClassZenkaku) {
+		return 1
+	}
+	return 0
+}
+
+func main() {
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	/*
+		#include <stdlib.h>
+		#include <string.h>
+		#include <mysql.h>
+	*/
+	"C"
+	"unsafe"
+
+	"github.com/ArmadaSuit/udf-go/converter"
+)
+
+//export udf_kana_check_init
+func udf_kana_check_init(initid *C.UDF_INIT, args *C.UDF_ARGS, message *C.char) C.bool {
+	if args.arg_count != 2 {
+		m := C.CString("2 arguments expected")
+		defer C.free(unsafe.Pointer(m))
+		C.strcpy(message, m)
+		return C.bool(true)
+	}
+
+	argsTypes := unsafe.Slice(args.arg_type, args.arg_count)
+
+	if argsTypes[0] != C.STRING_RESULT || argsTypes[1] != C.STRING_RESULT {
+		m := C.CString("2 arguments must be string")
+		defer C.free(unsafe.Pointer(m))
+		C.strcpy(message, m)
+		return C.bool(true)
+	}
+
+	argsArgs := unsafe.Slice(args.args, args.arg_count)
+	argsLengths := unsafe.Slice(args.lengths, args.arg_count)
+
+	if argsArgs[1] != nil {
+		_, err := converter.ParseKanaClass(C.GoStringN(argsArgs[1], C.int(argsLengths[1])))
+		if err != nil {
+			m := C.CString(err.Error())
+			defer C.free(unsafe.Pointer(m))
+			C.strcpy(message, m)
+			return C.bool(true)
+		}
+	}
+
+	initid.maybe_null = C.bool(true)
+
+	return C.bool(false)
+}
+
+//export udf_kana_check
+func udf_kana_check(initid *C.UDF_INIT, args *C.UDF_ARGS, isNull *C.char, err *C.char) C.longlong {
+	argsArgs := unsafe.Slice(args.args, args.arg_count)
+	argsLengths := unsafe.Slice(args.lengths, args.arg_count)
+	if argsArgs[0] == nil || argsArgs[1] == nil {
+		*isNull = 1
+		return 0
+	}
+
+	classes, e := converter.ParseKanaClass(C.GoStringN(argsArgs[1], C.int(argsLengths[1])))
+	if e != nil {
+		*err = 1
+		return 0
+	}
+
+	if converter.CheckKanaClass(C.GoStringN(argsArgs[0], C.int(argsLengths[0])), classes) {
+		return 1
+	}
+	return 0
+}
+
+func main() {
+}
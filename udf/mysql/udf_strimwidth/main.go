@@ -0,0 +1,82 @@
+package main
+
+import (
+	/*
+		#include <stdlib.h>
+		#include <string.h>
+		#include <mysql.h>
+	*/
+	"C"
+	"unsafe"
+
+	"github.com/ArmadaSuit/udf-go/converter"
+)
+
+//export udf_strimwidth_init
+func udf_strimwidth_init(initid *C.UDF_INIT, args *C.UDF_ARGS, message *C.char) C.bool {
+	if args.arg_count != 3 && args.arg_count != 4 {
+		m := C.CString("3 or 4 arguments expected")
+		defer C.free(unsafe.Pointer(m))
+		C.strcpy(message, m)
+		return C.bool(true)
+	}
+
+	argsTypes := unsafe.Slice(args.arg_type, args.arg_count)
+
+	if argsTypes[0] != C.STRING_RESULT || (args.arg_count == 4 && argsTypes[3] != C.STRING_RESULT) {
+		m := C.CString("text and trim marker must be string")
+		defer C.free(unsafe.Pointer(m))
+		C.strcpy(message, m)
+		return C.bool(true)
+	}
+
+	// the server converts start and width to integer, such as '10' to 10
+	argsTypes[1] = C.INT_RESULT
+	argsTypes[2] = C.INT_RESULT
+
+	initid.maybe_null = C.bool(true)
+	initid.ptr = nil
+
+	return C.bool(false)
+}
+
+//export udf_strimwidth_deinit
+func udf_strimwidth_deinit(initid *C.UDF_INIT) {
+	C.free(unsafe.Pointer(initid.ptr))
+}
+
+//export udf_strimwidth
+func udf_strimwidth(initid *C.UDF_INIT, args *C.UDF_ARGS, result *C.char, length *C.ulong, isNull *C.char, err *C.char) *C.char {
+	argsArgs := unsafe.Slice(args.args, args.arg_count)
+	argsLengths := unsafe.Slice(args.lengths, args.arg_count)
+	for _, a := range argsArgs {
+		if a == nil {
+			*isNull = 1
+			return nil
+		}
+	}
+
+	start := *(*C.longlong)(unsafe.Pointer(argsArgs[1]))
+	width := *(*C.longlong)(unsafe.Pointer(argsArgs[2]))
+	trimMarker := ""
+	if args.arg_count == 4 {
+		trimMarker = C.GoStringN(argsArgs[3], C.int(argsLengths[3]))
+	}
+
+	s, e := converter.TrimWidth(C.GoStringN(argsArgs[0], C.int(argsLengths[0])), int(start), int(width), trimMarker)
+	if e != nil {
+		// only this row is NULL, whereas err would make the following rows NULL too
+		*isNull = 1
+		return nil
+	}
+
+	// the result may be longer than the buffer given by the server
+	C.free(unsafe.Pointer(initid.ptr))
+	initid.ptr = (*C.char)(C.CBytes([]byte(s)))
+	*length = C.ulong(len(s))
+
+	return initid.ptr
+}
+
+func main() {
+}
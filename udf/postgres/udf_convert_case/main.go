@@ -0,0 +1,25 @@
+package main
+
+import (
+	/*
+		#include <postgres.h>
+
+		extern Datum udf_convert_case(PG_FUNCTION_ARGS);
+	*/
+	"C"
+
+	"github.com/ArmadaSuit/udf-go/udf/internal/cache"
+)
+
+//export udf_go_convert_case
+func udf_go_convert_case(text *C.char, mode *C.char) (*C.char, *C.char) {
+	c, err := cache.Case(C.GoString(mode))
+	if err != nil {
+		return nil, C.CString(err.Error())
+	}
+
+	return C.CString(c.Convert(C.GoString(text))), nil
+}
+
+func main() {
+}
@@ -17,18 +17,28 @@ udf_convert_kana(PG_FUNCTION_ARGS)
 	int32 raw_arg2_size = VARSIZE_ANY_EXHDR(raw_arg2);
 	char *arg1 = (char *) palloc(raw_arg1_size + 1);
 	char *arg2 = (char *) palloc(raw_arg2_size + 1);
+	char *arg3 = NULL;
 	strncpy(arg1, VARDATA_ANY(raw_arg1), raw_arg1_size);
 	strncpy(arg2, VARDATA_ANY(raw_arg2), raw_arg2_size);
     // text type is not null character terminated
 	arg1[raw_arg1_size] = '\0';
 	arg2[raw_arg2_size] = '\0';
 
-	struct udf_go_convert_kana_return r = udf_go_convert_kana(arg1, arg2);
+	// the third argument, the policy for invalid UTF-8, is optional
+	if (PG_NARGS() > 2) {
+		text  *raw_arg3 = PG_GETARG_TEXT_PP(2);
+		int32 raw_arg3_size = VARSIZE_ANY_EXHDR(raw_arg3);
+		arg3 = (char *) palloc(raw_arg3_size + 1);
+		strncpy(arg3, VARDATA_ANY(raw_arg3), raw_arg3_size);
+		arg3[raw_arg3_size] = '\0';
+	}
+
+	struct udf_go_convert_kana_return r = udf_go_convert_kana(arg1, arg2, arg3);
 	if (r.r1 != NULL) {
 		char *msg = (char *)palloc(strlen(r.r1) + 1);
 		strcpy(msg, r.r1);
 		free(r.r1);
-		ereport(ERROR, (errcode(ERRCODE_INVALID_PARAMETER_VALUE), errmsg("%s", msg)));
+		ereport(ERROR, (errcode(r.r2), errmsg("%s", msg)));
 	}
 
 	int32 new_text_size = strlen(r.r0) + VARHDRSZ;
@@ -38,4 +48,4 @@ udf_convert_kana(PG_FUNCTION_ARGS)
 	free(r.r0);
 
 	PG_RETURN_TEXT_P(new_text);
-}
\ No newline at end of file
+}
@@ -7,25 +7,36 @@ import (
 		extern Datum udf_convert_kana(PG_FUNCTION_ARGS);
 	*/
 	"C"
+	"errors"
 
 	"github.com/ArmadaSuit/udf-go/converter"
+	"github.com/ArmadaSuit/udf-go/udf/internal/cache"
 )
 
 //export udf_go_convert_kana
-func udf_go_convert_kana(text *C.char, mode *C.char) (*C.char, *C.char) {
-	converters, err := converter.NewKanaConverters(C.GoString(mode))
+func udf_go_convert_kana(text *C.char, mode *C.char, policy *C.char) (*C.char, *C.char, C.int) {
+	c, err := cache.Kana(C.GoString(mode))
 	if err != nil {
-		return nil, C.CString(err.Error())
+		return nil, C.CString(err.Error()), C.ERRCODE_INVALID_PARAMETER_VALUE
+	}
+	p := converter.ReplaceInvalidUTF8
+	if policy != nil {
+		p, err = converter.ParseInvalidUTF8Policy(C.GoString(policy))
+		if err != nil {
+			return nil, C.CString(err.Error()), C.ERRCODE_INVALID_PARAMETER_VALUE
+		}
 	}
 
-	s := C.GoString(text)
-	in := converter.GenerateForKanaConverter(s)
-	for _, c := range converters {
-		in = c(in)
+	str, err := c.ConvertWithPolicy(C.GoString(text), p)
+	if err != nil {
+		var e *converter.InvalidUTF8Error
+		if errors.As(err, &e) {
+			return nil, C.CString(err.Error()), C.ERRCODE_CHARACTER_NOT_IN_REPERTOIRE
+		}
+		return nil, C.CString(err.Error()), C.ERRCODE_INVALID_PARAMETER_VALUE
 	}
-	str := converter.StringForKanaConverter(in)
 
-	return C.CString(str), nil
+	return C.CString(str), nil, 0
 }
 
 func main() {
@@ -0,0 +1,36 @@
+package main
+
+import (
+	/*
+		#include <postgres.h>
+
+		extern Datum udf_convert_kana_explain(PG_FUNCTION_ARGS);
+	*/
+	"C"
+	"encoding/json"
+
+	"github.com/ArmadaSuit/udf-go/converter"
+	"github.com/ArmadaSuit/udf-go/udf/internal/cache"
+)
+
+//export udf_go_convert_kana_explain
+func udf_go_convert_kana_explain(text *C.char, mode *C.char) (*C.char, *C.char) {
+	c, err := cache.Kana(C.GoString(mode))
+	if err != nil {
+		return nil, C.CString(err.Error())
+	}
+
+	_, traces := c.Explain(C.GoString(text))
+	if traces == nil {
+		traces = []converter.KanaConverterTrace{}
+	}
+	b, err := json.Marshal(traces)
+	if err != nil {
+		return nil, C.CString(err.Error())
+	}
+
+	return C.CString(string(b)), nil
+}
+
+func main() {
+}
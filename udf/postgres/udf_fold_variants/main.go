@@ -0,0 +1,27 @@
+package main
+
+import (
+	/*
+		#include <postgres.h>
+
+		extern Datum udf_fold_variants(PG_FUNCTION_ARGS);
+	*/
+	"C"
+
+	"github.com/ArmadaSuit/udf-go/converter"
+)
+
+//export udf_go_fold_variants
+func udf_go_fold_variants(text *C.char) (*C.char, *C.char) {
+	if foldVariantsErr != nil {
+		return nil, C.CString(foldVariantsErr.Error())
+	}
+
+	return C.CString(foldVariants.Convert(C.GoString(text))), nil
+}
+
+// foldVariants is compiled once when the library is loaded.
+var foldVariants, foldVariantsErr = converter.Compile("+variant_itaiji+variant_historical_kana")
+
+func main() {
+}
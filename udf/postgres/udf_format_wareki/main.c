@@ -0,0 +1,46 @@
+#include <postgres.h>
+#include <fmgr.h>
+#include <stdlib.h>
+#include <string.h>
+#include "_cgo_export.h"
+
+PG_MODULE_MAGIC;
+
+PG_FUNCTION_INFO_V1(udf_format_wareki);
+
+Datum
+udf_format_wareki(PG_FUNCTION_ARGS)
+{
+	text  *raw_arg1 = PG_GETARG_TEXT_PP(0);
+	int32 raw_arg1_size = VARSIZE_ANY_EXHDR(raw_arg1);
+	char *arg1 = (char *) palloc(raw_arg1_size + 1);
+	char *arg2 = NULL;
+	strncpy(arg1, VARDATA_ANY(raw_arg1), raw_arg1_size);
+    // text type is not null character terminated
+	arg1[raw_arg1_size] = '\0';
+
+	// the second argument, the style, is optional
+	if (PG_NARGS() > 1) {
+		text  *raw_arg2 = PG_GETARG_TEXT_PP(1);
+		int32 raw_arg2_size = VARSIZE_ANY_EXHDR(raw_arg2);
+		arg2 = (char *) palloc(raw_arg2_size + 1);
+		strncpy(arg2, VARDATA_ANY(raw_arg2), raw_arg2_size);
+		arg2[raw_arg2_size] = '\0';
+	}
+
+	struct udf_go_format_wareki_return r = udf_go_format_wareki(arg1, arg2);
+	if (r.r1 != NULL) {
+		char *msg = (char *)palloc(strlen(r.r1) + 1);
+		strcpy(msg, r.r1);
+		free(r.r1);
+		ereport(ERROR, (errcode(r.r2), errmsg("%s", msg)));
+	}
+
+	int32 new_text_size = strlen(r.r0) + VARHDRSZ;
+	text *new_text = (text *) palloc(new_text_size);
+	SET_VARSIZE(new_text, new_text_size);
+	memcpy(VARDATA(new_text), r.r0, strlen(r.r0));
+	free(r.r0);
+
+	PG_RETURN_TEXT_P(new_text);
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	/*
+		#include <postgres.h>
+
+		extern Datum udf_format_wareki(PG_FUNCTION_ARGS);
+	*/
+	"C"
+
+	"github.com/ArmadaSuit/udf-go/converter"
+	"github.com/ArmadaSuit/udf-go/udf/internal/pgerrcode"
+)
+
+//export udf_go_format_wareki
+func udf_go_format_wareki(date *C.char, style *C.char) (*C.char, *C.char, C.int) {
+	s := converter.WarekiLong
+	if style != nil {
+		var err error
+		s, err = converter.ParseWarekiStyle(C.GoString(style))
+		if err != nil {
+			return nil, C.CString(err.Error()), C.ERRCODE_INVALID_PARAMETER_VALUE
+		}
+	}
+
+	t, err := converter.ParseJapaneseDate(C.GoString(date))
+	if err != nil {
+		return nil, C.CString(err.Error()), C.int(pgerrcode.Date(err))
+	}
+	w, err := converter.FormatWareki(t, s)
+	if err != nil {
+		return nil, C.CString(err.Error()), C.int(pgerrcode.Date(err))
+	}
+
+	return C.CString(w), nil, 0
+}
+
+func main() {
+}
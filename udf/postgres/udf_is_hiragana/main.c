@@ -0,0 +1,22 @@
+#include <postgres.h>
+#include <fmgr.h>
+#include <stdlib.h>
+#include <string.h>
+#include "_cgo_export.h"
+
+PG_MODULE_MAGIC;
+
+PG_FUNCTION_INFO_V1(udf_is_hiragana);
+
+Datum
+udf_is_hiragana(PG_FUNCTION_ARGS)
+{
+	text  *raw_arg1 = PG_GETARG_TEXT_PP(0);
+	int32 raw_arg1_size = VARSIZE_ANY_EXHDR(raw_arg1);
+	char *arg1 = (char *) palloc(raw_arg1_size + 1);
+	strncpy(arg1, VARDATA_ANY(raw_arg1), raw_arg1_size);
+    // text type is not null character terminated
+	arg1[raw_arg1_size] = '\0';
+
+	PG_RETURN_BOOL(udf_go_is_hiragana(arg1));
+}
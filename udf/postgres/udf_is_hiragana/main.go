@@ -0,0 +1,20 @@
+package main
+
+import (
+	/*
+		#include <postgres.h>
+
+		extern Datum udf_is_hiragana(PG_FUNCTION_ARGS);
+	*/
+	"C"
+
+	"github.com/ArmadaSuit/udf-go/converter"
+)
+
+//export udf_go_is_hiragana
+func udf_go_is_hiragana(text *C.char) C.bool {
+	return C.bool(converter.CheckKanaClass(C.GoString(text), converter.KanaClassHiragana))
+}
+
+func main() {
+}
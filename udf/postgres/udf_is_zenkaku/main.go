@@ -0,0 +1,20 @@
+package main
+
+import (
+	/*
+		#include <postgres.h>
+
+		extern Datum udf_is_zenkaku(PG_FUNCTION_ARGS);
+	*/
+	"C"
+
+	"github.com/ArmadaSuit/udf-go/converter"
+)
+
+//export udf_go_is_zenkaku
+func udf_go_is_zenkaku(text *C.char) C.bool {
+	return C.bool(converter.CheckKanaClass(C.GoString(text), converter.KanaClassZenkaku))
+}
+
+func main() {
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	/*
+		#include <postgres.h>
+
+		extern Datum udf_kana_check(PG_FUNCTION_ARGS);
+	*/
+	"C"
+
+	"github.com/ArmadaSuit/udf-go/converter"
+)
+
+//export udf_go_kana_check
+func udf_go_kana_check(text *C.char, classes *C.char) (C.bool, *C.char) {
+	c, err := converter.ParseKanaClass(C.GoString(classes))
+	if err != nil {
+		return false, C.CString(err.Error())
+	}
+
+	return C.bool(converter.CheckKanaClass(C.GoString(text), c)), nil
+}
+
+func main() {
+}
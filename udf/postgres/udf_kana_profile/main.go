@@ -0,0 +1,26 @@
+package main
+
+import (
+	/*
+		#include <postgres.h>
+
+		extern Datum udf_kana_profile(PG_FUNCTION_ARGS);
+	*/
+	"C"
+	"encoding/json"
+
+	"github.com/ArmadaSuit/udf-go/converter"
+)
+
+//export udf_go_kana_profile
+func udf_go_kana_profile(text *C.char) (*C.char, *C.char) {
+	b, err := json.Marshal(converter.ProfileKana(C.GoString(text)))
+	if err != nil {
+		return nil, C.CString(err.Error())
+	}
+
+	return C.CString(string(b)), nil
+}
+
+func main() {
+}
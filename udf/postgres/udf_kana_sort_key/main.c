@@ -0,0 +1,30 @@
+#include <postgres.h>
+#include <fmgr.h>
+#include <stdlib.h>
+#include <string.h>
+#include "_cgo_export.h"
+
+PG_MODULE_MAGIC;
+
+PG_FUNCTION_INFO_V1(udf_kana_sort_key);
+
+Datum
+udf_kana_sort_key(PG_FUNCTION_ARGS)
+{
+	text  *raw_arg1 = PG_GETARG_TEXT_PP(0);
+	int32 raw_arg1_size = VARSIZE_ANY_EXHDR(raw_arg1);
+	char *arg1 = (char *) palloc(raw_arg1_size + 1);
+	strncpy(arg1, VARDATA_ANY(raw_arg1), raw_arg1_size);
+    // text type is not null character terminated
+	arg1[raw_arg1_size] = '\0';
+
+	struct udf_go_kana_sort_key_return r = udf_go_kana_sort_key(arg1);
+
+	int32 new_bytea_size = r.r1 + VARHDRSZ;
+	bytea *new_bytea = (bytea *) palloc(new_bytea_size);
+	SET_VARSIZE(new_bytea, new_bytea_size);
+	memcpy(VARDATA(new_bytea), r.r0, r.r1);
+	free(r.r0);
+
+	PG_RETURN_BYTEA_P(new_bytea);
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	/*
+		#include <postgres.h>
+
+		extern Datum udf_kana_sort_key(PG_FUNCTION_ARGS);
+	*/
+	"C"
+	"unsafe"
+
+	"github.com/ArmadaSuit/udf-go/converter"
+)
+
+//export udf_go_kana_sort_key
+func udf_go_kana_sort_key(text *C.char) (unsafe.Pointer, C.int) {
+	// the key is binary, so its length is returned with it
+	b := converter.KanaSortKey(C.GoString(text))
+
+	return C.CBytes(b), C.int(len(b))
+}
+
+func main() {
+}
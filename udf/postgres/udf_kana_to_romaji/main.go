@@ -0,0 +1,25 @@
+package main
+
+import (
+	/*
+		#include <postgres.h>
+
+		extern Datum udf_kana_to_romaji(PG_FUNCTION_ARGS);
+	*/
+	"C"
+
+	"github.com/ArmadaSuit/udf-go/udf/internal/cache"
+)
+
+//export udf_go_kana_to_romaji
+func udf_go_kana_to_romaji(text *C.char, style *C.char) (*C.char, *C.char) {
+	c, err := cache.Romaji(C.GoString(style))
+	if err != nil {
+		return nil, C.CString(err.Error())
+	}
+
+	return C.CString(c.Convert(C.GoString(text))), nil
+}
+
+func main() {
+}
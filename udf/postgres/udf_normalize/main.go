@@ -0,0 +1,25 @@
+package main
+
+import (
+	/*
+		#include <postgres.h>
+
+		extern Datum udf_normalize(PG_FUNCTION_ARGS);
+	*/
+	"C"
+
+	"github.com/ArmadaSuit/udf-go/converter"
+)
+
+//export udf_go_normalize
+func udf_go_normalize(text *C.char, form *C.char) (*C.char, *C.char) {
+	f, err := converter.ParseNormalizationForm(C.GoString(form))
+	if err != nil {
+		return nil, C.CString(err.Error())
+	}
+
+	return C.CString(converter.Normalize(C.GoString(text), f)), nil
+}
+
+func main() {
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	/*
+		#include <postgres.h>
+
+		extern Datum udf_parse_jdate(PG_FUNCTION_ARGS);
+	*/
+	"C"
+
+	"github.com/ArmadaSuit/udf-go/converter"
+	"github.com/ArmadaSuit/udf-go/udf/internal/pgerrcode"
+)
+
+//export udf_go_parse_jdate
+func udf_go_parse_jdate(text *C.char) (*C.char, *C.char, C.int) {
+	t, err := converter.ParseJapaneseDate(C.GoString(text))
+	if err != nil {
+		return nil, C.CString(err.Error()), C.int(pgerrcode.Date(err))
+	}
+
+	return C.CString(t.Format("2006-01-02")), nil, 0
+}
+
+func main() {
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	/*
+		#include <postgres.h>
+
+		extern Datum udf_romaji_to_kana(PG_FUNCTION_ARGS);
+	*/
+	"C"
+
+	"github.com/ArmadaSuit/udf-go/converter"
+)
+
+//export udf_go_romaji_to_kana
+func udf_go_romaji_to_kana(text *C.char) (*C.char, *C.char) {
+	if romajiToKanaErr != nil {
+		return nil, C.CString(romajiToKanaErr.Error())
+	}
+
+	return C.CString(romajiToKana.Convert(C.GoString(text))), nil
+}
+
+// romajiToKana is compiled once when the library is loaded.
+var romajiToKana, romajiToKanaErr = converter.CompileRomajiToKana()
+
+func main() {
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	/*
+		#include <postgres.h>
+
+		extern Datum udf_strimwidth(PG_FUNCTION_ARGS);
+	*/
+	"C"
+
+	"github.com/ArmadaSuit/udf-go/converter"
+)
+
+//export udf_go_strimwidth
+func udf_go_strimwidth(text *C.char, start C.int, width C.int, trimMarker *C.char) (*C.char, *C.char) {
+	m := ""
+	if trimMarker != nil {
+		m = C.GoString(trimMarker)
+	}
+
+	s, err := converter.TrimWidth(C.GoString(text), int(start), int(width), m)
+	if err != nil {
+		return nil, C.CString(err.Error())
+	}
+
+	return C.CString(s), nil
+}
+
+func main() {
+}
@@ -0,0 +1,20 @@
+package main
+
+import (
+	/*
+		#include <postgres.h>
+
+		extern Datum udf_strwidth(PG_FUNCTION_ARGS);
+	*/
+	"C"
+
+	"github.com/ArmadaSuit/udf-go/converter"
+)
+
+//export udf_go_strwidth
+func udf_go_strwidth(text *C.char) C.int {
+	return C.int(converter.StringWidth(C.GoString(text)))
+}
+
+func main() {
+}